@@ -25,6 +25,7 @@ const emailSchema = z.object({
 });
 
 const passwordSchema = z.object({
+  currentPassword: z.string().min(1, 'Current password is required'),
   password: z.string()
     .min(8, 'Password must be at least 8 characters')
     .regex(/^(?=.*[a-z])(?=.*[A-Z])(?=.*\d)/, 'Password must contain at least one uppercase letter, one lowercase letter, and one number'),
@@ -39,10 +40,11 @@ type EmailForm = z.infer<typeof emailSchema>;
 type PasswordForm = z.infer<typeof passwordSchema>;
 
 export default function ProfilePage() {
-  const { user, updateProfile } = useAuth();
+  const { user, updateProfile, changePassword } = useAuth();
   const [isUpdatingName, setIsUpdatingName] = useState(false);
   const [isUpdatingEmail, setIsUpdatingEmail] = useState(false);
   const [isUpdatingPassword, setIsUpdatingPassword] = useState(false);
+  const [showCurrentPassword, setShowCurrentPassword] = useState(false);
   const [showPassword, setShowPassword] = useState(false);
   const [showConfirmPassword, setShowConfirmPassword] = useState(false);
   const [expandedCard, setExpandedCard] = useState<string | null>(null);
@@ -64,6 +66,7 @@ export default function ProfilePage() {
   const passwordForm = useForm<PasswordForm>({
     resolver: zodResolver(passwordSchema),
     defaultValues: {
+      currentPassword: '',
       password: '',
       confirmPassword: '',
     },
@@ -87,10 +90,12 @@ export default function ProfilePage() {
     setIsUpdatingEmail(true);
     try {
       await updateProfile('email', data.email);
-      toast.success('Email updated successfully');
+      toast.success('Confirmation email sent', {
+        description: 'Open the link sent to your new address to finish the change',
+      });
     } catch (error: any) {
       toast.error('Failed to update email', {
-        description: error.response?.data?.message || 'Please try again',
+        description: error.response?.data?.error || error.response?.data?.message || 'Please try again',
       });
     } finally {
       setIsUpdatingEmail(false);
@@ -100,12 +105,14 @@ export default function ProfilePage() {
   const onUpdatePassword = async (data: PasswordForm) => {
     setIsUpdatingPassword(true);
     try {
-      await updateProfile('password', data.password);
-      toast.success('Password updated successfully');
+      await changePassword(data.currentPassword, data.password);
+      toast.success('Password updated successfully', {
+        description: 'Your other sessions have been signed out',
+      });
       passwordForm.reset();
     } catch (error: any) {
       toast.error('Failed to update password', {
-        description: error.response?.data?.message || 'Please try again',
+        description: error.response?.data?.error || error.response?.data?.message || 'Please try again',
       });
     } finally {
       setIsUpdatingPassword(false);
@@ -358,9 +365,43 @@ export default function ProfilePage() {
                 </CardDescription>
               </CardHeader>
               <CardContent className={`transition-all duration-500 overflow-hidden ${
-                expandedCard === 'password' ? 'max-h-[600px] p-8' : 'max-h-0 p-0'
+                expandedCard === 'password' ? 'max-h-[800px] p-8' : 'max-h-0 p-0'
               }`}>
                 <form onSubmit={passwordForm.handleSubmit(onUpdatePassword)} className="space-y-6">
+                  <div className="space-y-3">
+                    <Label htmlFor="currentPassword" className="text-base font-semibold text-gray-700">Current Password</Label>
+                    <div className="relative">
+                      <Input
+                        id="currentPassword"
+                        type={showCurrentPassword ? 'text' : 'password'}
+                        placeholder="Enter current password"
+                        {...passwordForm.register('currentPassword')}
+                        className="h-12 text-lg border-2 border-gray-200 focus:border-purple-500 transition-all duration-300 rounded-xl pr-12"
+                      />
+                      <Button
+                        type="button"
+                        variant="ghost"
+                        size="sm"
+                        className="absolute right-0 top-0 h-full px-4 hover:bg-transparent"
+                        onClick={() => setShowCurrentPassword(!showCurrentPassword)}
+                      >
+                        {showCurrentPassword ? (
+                          <EyeOff className="h-5 w-5 text-gray-400 hover:text-gray-600 transition-colors" />
+                        ) : (
+                          <Eye className="h-5 w-5 text-gray-400 hover:text-gray-600 transition-colors" />
+                        )}
+                      </Button>
+                    </div>
+                    {passwordForm.formState.errors.currentPassword && (
+                      <div className="flex items-center gap-2 text-red-600 animate-in fade-in duration-300">
+                        <AlertCircle className="h-4 w-4" />
+                        <p className="text-sm font-medium">
+                          {passwordForm.formState.errors.currentPassword.message}
+                        </p>
+                      </div>
+                    )}
+                  </div>
+
                   <div className="space-y-3">
                     <Label htmlFor="password" className="text-base font-semibold text-gray-700">New Password</Label>
                     <div className="relative">
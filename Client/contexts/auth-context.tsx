@@ -10,7 +10,8 @@ interface AuthContextType {
   login: (email: string, password: string) => Promise<void>;
   register: (name: string, email: string, password: string) => Promise<void>;
   logout: () => Promise<void>;
-  updateProfile: (type: 'name' | 'email' | 'password', value: string) => Promise<void>;
+  updateProfile: (type: 'name' | 'email', value: string) => Promise<void>;
+  changePassword: (currentPassword: string, newPassword: string) => Promise<void>;
   refreshProfile: () => Promise<void>;
 }
 
@@ -85,12 +86,19 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
     }
   };
 
-  const updateProfile = async (type: 'name' | 'email' | 'password', value: string) => {
-    await authService.updateProfile(type, value);
-    if (type !== 'password') {
-      // Refresh profile to get updated data
-      await refreshProfile();
+  const updateProfile = async (type: 'name' | 'email', value: string) => {
+    if (type === 'email') {
+      // The new address only applies once confirmed from its inbox
+      await authService.changeEmail(value);
+      return;
     }
+    await authService.updateProfile(type, value);
+    // Refresh profile to get updated data
+    await refreshProfile();
+  };
+
+  const changePassword = async (currentPassword: string, newPassword: string) => {
+    await authService.changePassword(currentPassword, newPassword);
   };
 
   const refreshProfile = async () => {
@@ -115,6 +123,7 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
         register,
         logout,
         updateProfile,
+        changePassword,
         refreshProfile,
       }}
     >
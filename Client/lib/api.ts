@@ -61,7 +61,9 @@ api.interceptors.response.use(
     
     const originalRequest = error.config as any;
     
-    if (error.response?.status === 401 && originalRequest && !originalRequest._retry) {
+    // Auth endpoints answer 401 for bad credentials, which a token refresh can't fix
+    const isAuthRequest = originalRequest?.url?.startsWith('/auth/');
+    if (error.response?.status === 401 && originalRequest && !originalRequest._retry && !isAuthRequest) {
       console.log('🔄 Token expired, attempting refresh...');
       originalRequest._retry = true;
       
@@ -54,7 +54,7 @@ export const authService = {
     return response.data;
   },
 
-  async updateProfile(type: 'name' | 'email' | 'password', value: string): Promise<void> {
+  async updateProfile(type: 'name', value: string): Promise<void> {
     console.log('📡 Calling update profile API...');
     await api.patch('/user/update', {
       type,
@@ -63,6 +63,24 @@ export const authService = {
     console.log('✅ Profile updated');
   },
 
+  async changeEmail(email: string): Promise<void> {
+    console.log('📡 Calling change email API...');
+    await api.patch('/user/email', { email });
+    console.log('✅ Email change confirmation sent');
+  },
+
+  async changePassword(currentPassword: string, newPassword: string): Promise<void> {
+    console.log('📡 Calling change password API...');
+    const response = await api.patch('/user/password', {
+      current_password: currentPassword,
+      new_password: newPassword,
+    });
+    // Other sessions are revoked, so keep this one going with the fresh tokens
+    const { accessToken, refreshToken } = response.data;
+    tokenManager.setTokens(accessToken, refreshToken);
+    console.log('✅ Password changed');
+  },
+
   isAuthenticated(): boolean {
     const hasToken = !!tokenManager.getAccessToken();
     console.log('🔍 Checking authentication:', hasToken);
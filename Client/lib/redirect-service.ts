@@ -35,11 +35,15 @@ export const redirectService = {
     // Check if this is a unique visit
     const isUnique = this.checkAndMarkUnique(slug);
 
+    // Forwarded to the destination when the link allows it
+    const query = window.location.search.replace(/^\?/, '');
+
     return {
       device,
       referrer,
       utm,
       isUnique,
+      query,
     };
   },
 
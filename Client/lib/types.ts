@@ -89,4 +89,5 @@ export interface RedirectReq {
   referrer: string;
   utm: UTMReq;
   isUnique: boolean;
+  query: string;
 }
\ No newline at end of file
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// Time a user has to change their mind before the account is really deleted
+	accountDeletionGraceDays = 30
+	// Keeps a well-known short URL from being taken over right away
+	slugQuarantineDays   = 90
+	accountPurgeInterval = time.Hour
+	exportClickBatchSize = 5000
+)
+
+var errSlugQuarantined = errors.New("slug is not available")
+
+// Rejects slugs that were freed by a deleted account and are still in quarantine
+func (cfg *apiCfg) checkSlugQuarantine(c *gin.Context, slug string) error {
+	quarantined, err := cfg.db.IsSlugQuarantined(c, slug)
+	if err != nil {
+		return err
+	}
+	if quarantined {
+		return errSlugQuarantined
+	}
+	return nil
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Reads the account's clicks a batch at a time, continuing after the given click
+func (cfg *apiCfg) exportClicksAfter(ctx context.Context, userID uuid.UUID, after *database.ExportClicksByUserIdRow) ([]database.ExportClicksByUserIdRow, error) {
+	params := database.ExportClicksByUserIdParams{UserID: userID, BatchSize: exportClickBatchSize}
+	if after != nil {
+		params.AfterID = uuid.NullUUID{UUID: after.ID, Valid: true}
+		params.AfterTime = sql.NullTime{Time: after.CreatedAt, Valid: true}
+	}
+	return cfg.db.ExportClicksByUserId(ctx, params)
+}
+
+// Writes the clicks a batch at a time so they are never all held in memory
+func (cfg *apiCfg) writeClicksCSV(ctx context.Context, zw *zip.Writer, name string, userID uuid.UUID, clicks []database.ExportClicksByUserIdRow) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"slug", "created_at", "ip_address", "country", "referrer", "is_unique",
+		"utm_source", "utm_medium", "utm_campaign",
+		"device_type", "platform", "language", "resolution", "timezone", "user_agent",
+	})
+	for len(clicks) > 0 {
+		for _, click := range clicks {
+			cw.Write([]string{
+				click.Slug, click.CreatedAt.Format(time.RFC3339), click.IpAddress, click.Country, click.Referrer,
+				strconv.FormatBool(click.IsUnique),
+				click.UtmSource, click.UtmMedium, click.UtmCampaign,
+				click.DeviceType, click.Platform, click.Language, click.Resolution, click.Timezone, click.UserAgent,
+			})
+		}
+		if len(clicks) < exportClickBatchSize {
+			break
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		if clicks, err = cfg.exportClicksAfter(ctx, userID, &clicks[len(clicks)-1]); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Sends everything stored about the user as a ZIP; clicks are streamed as CSV
+func (cfg *apiCfg) ExportAccount(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	identities, err := cfg.db.RetrieveUserIdentitiesByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	attempts, err := cfg.db.ExportLoginAttemptsByUserId(c, uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	links, err := cfg.db.ExportShortLinksByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	tags, err := cfg.db.ListTagsByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	folders, err := cfg.db.ListFoldersByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	campaigns, err := cfg.db.ListCampaignsByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	revisions, err := cfg.db.ExportLinkRevisionsByAccountId(c, uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	clicks, err := cfg.exportClicksAfter(c, user.ID, nil)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	profile := UserInfoReq{
+		Id:                   user.ID,
+		Name:                 user.Name,
+		Email:                user.Email,
+		EmailVerified:        user.EmailVerified,
+		MfaEnabled:           user.MfaEnabled,
+		CreatedAt:            user.CreatedAt,
+		DeletionScheduledFor: nullTimePtr(user.DeletionScheduledAt),
+		PrivacyMode:          user.PrivacyMode,
+	}
+	identityData := []ExportIdentity{}
+	for _, identity := range identities {
+		identityData = append(identityData, ExportIdentity{
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+	signInData := []SignInRes{}
+	for _, attempt := range attempts {
+		signInData = append(signInData, SignInRes{
+			IPAddress: attempt.IpAddress,
+			UserAgent: attempt.UserAgent,
+			Success:   attempt.Success,
+			CreatedAt: attempt.CreatedAt,
+		})
+	}
+	linkData := []ExportLink{}
+	for _, link := range links {
+		linkData = append(linkData, ExportLink{
+			Slug:        link.Slug,
+			OriginalURL: link.OriginalUrl,
+			Title:       link.Title,
+			IsActive:    link.IsActive.Bool,
+			UTMSource:   link.UtmSource,
+			UTMMedium:   link.UtmMedium,
+			UTMCampaign: link.UtmCampaign,
+			UTMTerm:     link.UtmTerm,
+			UTMContent:  link.UtmContent,
+			ExtraParams: decodeExtraParams(link.ExtraParams),
+			UTMPolicy:   link.UtmPolicy,
+			PassQuery:   link.PassQuery,
+			Tags:        link.Tags,
+			FolderID:    uuidPtr(link.FolderID),
+			CampaignID:  uuidPtr(link.CampaignID),
+			PrivacyMode: link.PrivacyMode,
+			CreatedAt:   link.CreatedAt,
+			UpdatedAt:   nullTimePtr(link.UpdatedAt),
+			DeletedAt:   nullTimePtr(link.DeletedAt),
+		})
+	}
+	revisionData := []ExportRevision{}
+	for _, revision := range revisions {
+		revisionData = append(revisionData, ExportRevision{
+			LinkID:    revision.TargetID,
+			Action:    revision.Action,
+			ActorID:   uuidPtr(revision.ActorID),
+			Before:    revision.Before,
+			After:     revision.After,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+
+	filename := fmt.Sprintf("quicklink-export-%s.zip", time.Now().UTC().Format("2006-01-02"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	zw := zip.NewWriter(c.Writer)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", profile},
+		{"identities.json", identityData},
+		{"sign_ins.json", signInData},
+		{"links.json", linkData},
+		{"revisions.json", revisionData},
+		{"tags.json", tagListRes(tags)},
+		{"folders.json", folderListRes(folders)},
+		{"campaigns.json", campaignListRes(campaigns)},
+	}
+	for _, file := range files {
+		if err := writeZipJSON(zw, file.name, file.data); err != nil {
+			log.Printf("Failed to write account export: %v", err)
+			return
+		}
+	}
+	if err := cfg.writeClicksCSV(c, zw, "clicks.csv", user.ID, clicks); err != nil {
+		log.Printf("Failed to write account export: %v", err)
+		return
+	}
+	if err := zw.Close(); err != nil {
+		log.Printf("Failed to write account export: %v", err)
+	}
+}
+
+// Schedules the account for deletion; it can be cancelled during the grace period
+func (cfg *apiCfg) DeleteAccount(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data PasswordConfirmReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(data.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password does not match"})
+		return
+	}
+	scheduledAt, err := cfg.db.ScheduleUserDeletion(c, database.ScheduleUserDeletionParams{
+		GraceDays: accountDeletionGraceDays,
+		ID:        user.ID,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	notice := fmt.Sprintf("Hi %s,\n\nYour account and all of its links and analytics will be deleted on %s. Sign in and cancel the deletion before then if you want to keep it.",
+		user.Name, scheduledAt.Time.Format("January 2, 2006"))
+	if err := cfg.mailer.Send(user.Email, "Your account is scheduled for deletion", notice); err != nil {
+		log.Printf("Failed to send account deletion notice: %v", err)
+	}
+	c.JSON(http.StatusOK, AccountDeletionRes{Success: true, DeletionScheduledFor: scheduledAt.Time})
+}
+
+func (cfg *apiCfg) CancelAccountDeletion(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	if !user.DeletionScheduledAt.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account is not scheduled for deletion"})
+		return
+	}
+	if err := cfg.db.CancelUserDeletion(c, user.ID); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// Deletes accounts past their grace period after quarantining their slugs
+func (cfg *apiCfg) purgeDeletedAccounts(ctx context.Context) error {
+	ids, err := cfg.db.ListUsersDueForDeletion(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := cfg.purgeAccount(ctx, id); err != nil {
+			return err
+		}
+	}
+	_, err = cfg.db.DeleteReleasedQuarantinedSlugs(ctx)
+	return err
+}
+
+func (cfg *apiCfg) purgeAccount(ctx context.Context, userID uuid.UUID) error {
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := cfg.db.WithTx(tx)
+	if err := q.QuarantineUserSlugs(ctx, database.QuarantineUserSlugsParams{
+		QuarantineDays: slugQuarantineDays,
+		UserID:         userID,
+	}); err != nil {
+		return err
+	}
+	// The log keeps what happened to the account, but not who it was
+	if err := q.ScrubAuditLogAccount(ctx, userID); err != nil {
+		return err
+	}
+	if err := q.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	roleUser  = "user"
+	roleAdmin = "admin"
+
+	maxModerationReason = 500
+)
+
+var errAccountSuspended = errors.New("This account has been suspended")
+
+var adminLinkStatuses = map[string]bool{
+	"active":      true,
+	"disabled":    true,
+	"blocked":     true,
+	"quarantined": true,
+	"deleted":     true,
+}
+
+// Binds the reason every suspension, block and quarantine has to come with
+func bindModerationReason(c *gin.Context) (string, bool) {
+	var data ModerationReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return "", false
+	}
+	reason := strings.TrimSpace(data.Reason)
+	if err := validateLabel("reason", reason, maxModerationReason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return reason, true
+}
+
+func (cfg *apiCfg) GetSystemStats(c *gin.Context) {
+	stats, err := cfg.db.GetSystemStats(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SystemStatsRes{
+		Users:                    stats.Users,
+		SuspendedUsers:           stats.SuspendedUsers,
+		NewUsers7d:               stats.NewUsers7d,
+		Links:                    stats.Links,
+		ActiveLinks:              stats.ActiveLinks,
+		QuarantinedLinks:         stats.QuarantinedLinks,
+		BlockedLinks:             stats.BlockedLinks,
+		Clicks24h:                stats.Clicks24h,
+		OpenReports:              stats.OpenReports,
+		PendingWebhookDeliveries: stats.PendingWebhookDeliveries,
+	})
+}
+
+// q matches name or email; suspended=true|false narrows by state
+func (cfg *apiCfg) AdminGetUsers(c *gin.Context) {
+	limit, offset, err := offsetPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params := database.AdminListUsersParams{
+		Search:     nullQuery(c, "q"),
+		PageLimit:  limit,
+		PageOffset: offset,
+	}
+	switch c.Query("suspended") {
+	case "":
+	case "true":
+		params.Suspended = sql.NullBool{Bool: true, Valid: true}
+	case "false":
+		params.Suspended = sql.NullBool{Bool: false, Valid: true}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "suspended must be true or false"})
+		return
+	}
+	users, err := cfg.db.AdminListUsers(c, params)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data := []AdminUserRes{}
+	for _, user := range users {
+		data = append(data, AdminUserRes{
+			ID:               user.ID,
+			Name:             user.Name,
+			Email:            user.Email,
+			Role:             user.Role,
+			EmailVerified:    user.EmailVerified,
+			SuspendedAt:      nullTimePtr(user.SuspendedAt),
+			SuspensionReason: user.SuspensionReason,
+			LinkCount:        user.LinkCount,
+			CreatedAt:        user.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+func (cfg *apiCfg) adminUserFromParam(c *gin.Context) (database.User, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return database.User{}, false
+	}
+	user, err := cfg.db.RetrieveUserById(c, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return database.User{}, false
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return database.User{}, false
+	}
+	return user, true
+}
+
+// Signs the account out and stops its links until the suspension is lifted
+func (cfg *apiCfg) AdminSuspendUser(c *gin.Context) {
+	admin := sortMiddlewareAuth(c)
+	target, ok := cfg.adminUserFromParam(c)
+	if !ok {
+		return
+	}
+	reason, ok := bindModerationReason(c)
+	if !ok {
+		return
+	}
+	if target.ID == admin.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't suspend your own account"})
+		return
+	}
+	if target.Role == roleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Revoke admin access before suspending this account"})
+		return
+	}
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		suspended, err := q.SuspendUser(c, database.SuspendUserParams{
+			ID:               target.ID,
+			SuspensionReason: reason,
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		if err := q.DeleteToken(c, target.ID); err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     auditUserSuspend,
+			TargetType: auditTargetUser,
+			TargetID:   target.ID.String(),
+			AccountID:  target.ID,
+			Before:     userAuditState(target),
+			After:      userAuditState(suspended),
+		}, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "user is already suspended"})
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) AdminUnsuspendUser(c *gin.Context) {
+	target, ok := cfg.adminUserFromParam(c)
+	if !ok {
+		return
+	}
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		restored, err := q.UnsuspendUser(c, target.ID)
+		if err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     auditUserUnsuspend,
+			TargetType: auditTargetUser,
+			TargetID:   target.ID.String(),
+			AccountID:  target.ID,
+			Before:     userAuditState(target),
+			After:      userAuditState(restored),
+		}, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "user is not suspended"})
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// q matches slug or destination; status is active, disabled, blocked, quarantined or deleted
+func (cfg *apiCfg) AdminGetLinks(c *gin.Context) {
+	limit, offset, err := offsetPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params := database.AdminListLinksParams{
+		Search:     nullQuery(c, "q"),
+		Status:     nullQuery(c, "status"),
+		PageLimit:  limit,
+		PageOffset: offset,
+	}
+	if params.Status.Valid && !adminLinkStatuses[params.Status.String] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, disabled, blocked, quarantined or deleted"})
+		return
+	}
+	if params.UserID, err = queryUUID(c, "user_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	links, err := cfg.db.AdminListLinks(c, params)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data := []AdminLinkRes{}
+	for _, link := range links {
+		data = append(data, AdminLinkRes{
+			ID:               link.ID,
+			Slug:             link.Slug,
+			OriginalURL:      link.OriginalUrl,
+			OwnerID:          link.UserID,
+			OwnerEmail:       link.OwnerEmail,
+			IsActive:         link.IsActive.Bool,
+			BlockedAt:        nullTimePtr(link.BlockedAt),
+			BlockedReason:    link.BlockedReason,
+			QuarantinedAt:    nullTimePtr(link.QuarantinedAt),
+			QuarantineReason: link.QuarantineReason,
+			QuarantinedBy:    link.QuarantinedBy,
+			DeletedAt:        nullTimePtr(link.DeletedAt),
+			CreatedAt:        link.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// Includes trashed links, which can still be restored
+func (cfg *apiCfg) adminLinkFromParam(c *gin.Context) (database.ShortLink, bool) {
+	link, err := cfg.db.RetrieveShortLinkBySlugWithTrashed(c, c.Param("slug"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		return link, false
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return link, false
+	}
+	return link, true
+}
+
+// Applies and audits a moderation change; conflict is returned when it doesn't apply
+func (cfg *apiCfg) moderateLink(c *gin.Context, link database.ShortLink, action string, event string, conflict string, change func(q *database.Queries) (database.ShortLink, error)) (database.ShortLink, bool) {
+	var changed database.ShortLink
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		var err error
+		changed, err = change(q)
+		if err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     action,
+			TargetType: auditTargetLink,
+			TargetID:   link.ID.String(),
+			AccountID:  link.UserID,
+			Before:     linkAuditState(link),
+			After:      linkAuditState(changed),
+		}, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+		return changed, false
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return changed, false
+	}
+	cfg.emitLinkEvent(c, event, changed)
+	return changed, true
+}
+
+// Stops the link from redirecting. Unlike switching it off, its owner can't undo it.
+func (cfg *apiCfg) AdminBlockLink(c *gin.Context) {
+	link, ok := cfg.adminLinkFromParam(c)
+	if !ok {
+		return
+	}
+	reason, ok := bindModerationReason(c)
+	if !ok {
+		return
+	}
+	blocked, ok := cfg.moderateLink(c, link, auditLinkBlock, webhookLinkUpdated, "link is already blocked", func(q *database.Queries) (database.ShortLink, error) {
+		return q.BlockShortLink(c, database.BlockShortLinkParams{
+			ID:            link.ID,
+			BlockedReason: reason,
+		})
+	})
+	if !ok {
+		return
+	}
+	cfg.notifyModeration(c, blocked, "Link /"+blocked.Slug+" blocked",
+		"has been blocked by our moderation team: "+reason+". Visitors no longer reach its destination.")
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) AdminUnblockLink(c *gin.Context) {
+	link, ok := cfg.adminLinkFromParam(c)
+	if !ok {
+		return
+	}
+	if _, ok := cfg.moderateLink(c, link, auditLinkUnblock, webhookLinkUpdated, "link is not blocked", func(q *database.Queries) (database.ShortLink, error) {
+		return q.UnblockShortLink(c, link.ID)
+	}); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// The scanner never releases a link an admin quarantined
+func (cfg *apiCfg) AdminQuarantineLink(c *gin.Context) {
+	link, ok := cfg.adminLinkFromParam(c)
+	if !ok {
+		return
+	}
+	reason, ok := bindModerationReason(c)
+	if !ok {
+		return
+	}
+	quarantined, ok := cfg.moderateLink(c, link, auditLinkQuarantine, webhookLinkQuarantined, "link is already quarantined", func(q *database.Queries) (database.ShortLink, error) {
+		return q.QuarantineShortLink(c, database.QuarantineShortLinkParams{
+			ID:               link.ID,
+			QuarantineReason: reason,
+			QuarantinedBy:    quarantinedByAdmin,
+		})
+	})
+	if !ok {
+		return
+	}
+	cfg.notifyQuarantine(c, quarantined)
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// A destination still listed is quarantined again by the next scan
+func (cfg *apiCfg) AdminReleaseLink(c *gin.Context) {
+	link, ok := cfg.adminLinkFromParam(c)
+	if !ok {
+		return
+	}
+	if _, ok := cfg.moderateLink(c, link, auditLinkRelease, webhookLinkUpdated, "link is not quarantined", func(q *database.Queries) (database.ShortLink, error) {
+		return q.ClearShortLinkQuarantine(c, link.ID)
+	}); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// what completes "Your link /slug to url ..."
+func (cfg *apiCfg) notifyModeration(ctx context.Context, link database.ShortLink, subject string, what string) {
+	user, err := cfg.db.RetrieveUserById(ctx, link.UserID)
+	if err != nil {
+		log.Printf("Failed to load link owner: %v", err)
+		return
+	}
+	body := fmt.Sprintf("Hi %s,\n\nYour link /%s to %s %s\n\nIf you believe this is a mistake, reply to this email.",
+		user.Name, link.Slug, link.OriginalUrl, what)
+	if err := cfg.mailer.Send(user.Email, subject, body); err != nil {
+		log.Printf("Failed to send moderation email: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	minAlertWindowMinutes = 5
+	maxAlertWindowMinutes = 7 * 24 * 60
+	maxAlertRulesPerLink  = 10
+)
+
+// Validates an alert rule and fills in the default window for its type
+func parseAlertRuleReq(data AlertRuleReq) (AlertRuleReq, error) {
+	data.Channel = defaultString(data.Channel, alertChannelEmail)
+	if data.Channel != alertChannelEmail && data.Channel != alertChannelWebhook {
+		return data, errors.New("channel must be email or webhook")
+	}
+	switch data.Type {
+	case alertClicksAbove:
+		if data.Threshold < 1 {
+			return data, errors.New("threshold must be at least 1")
+		}
+		if data.WindowMinutes == 0 {
+			data.WindowMinutes = 60
+		}
+	case alertNoClicks:
+		data.Threshold = 0
+		if data.WindowMinutes == 0 {
+			data.WindowMinutes = 24 * 60
+		}
+	case alertNewCountry:
+		data.Threshold = 0
+		data.WindowMinutes = 0
+		return data, nil
+	case alertMaxClicks:
+		if data.Threshold < 1 {
+			return data, errors.New("threshold must be at least 1")
+		}
+		data.WindowMinutes = 0
+		return data, nil
+	default:
+		return data, errors.New("type must be clicks_above, no_clicks, new_country or max_clicks")
+	}
+	if data.WindowMinutes < minAlertWindowMinutes || data.WindowMinutes > maxAlertWindowMinutes {
+		return data, errors.New("window_minutes must be between 5 and 10080")
+	}
+	return data, nil
+}
+
+func (cfg *apiCfg) GetAlertRules(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	rules, err := cfg.db.ListAlertRulesByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data := []AlertRuleRes{}
+	for _, rule := range rules {
+		data = append(data, AlertRuleRes{
+			ID:              rule.ID,
+			Slug:            rule.Slug,
+			Type:            rule.Type,
+			Threshold:       rule.Threshold,
+			WindowMinutes:   rule.WindowMinutes,
+			Channel:         rule.Channel,
+			IsMuted:         rule.IsMuted,
+			LastTriggeredAt: nullTimePtr(rule.LastTriggeredAt),
+			CreatedAt:       rule.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+func (cfg *apiCfg) CreateAlertRule(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data AlertRuleReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	data, err := parseAlertRuleReq(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	link, err := cfg.db.RetrieveShortLinkBySlugNUserId(c, database.RetrieveShortLinkBySlugNUserIdParams{
+		UserID: user.ID,
+		Slug:   data.Slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	existing, err := cfg.db.ListAlertRulesByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	count := 0
+	for _, rule := range existing {
+		if rule.ShortLinkID == link.ID {
+			count++
+		}
+	}
+	if count >= maxAlertRulesPerLink {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alert limit reached for this link"})
+		return
+	}
+	rule, err := cfg.db.CreateAlertRule(c, database.CreateAlertRuleParams{
+		UserID:        user.ID,
+		ShortLinkID:   link.ID,
+		Type:          data.Type,
+		Threshold:     data.Threshold,
+		WindowMinutes: data.WindowMinutes,
+		Channel:       data.Channel,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, AlertRuleRes{
+		ID:            rule.ID,
+		Slug:          link.Slug,
+		Type:          rule.Type,
+		Threshold:     rule.Threshold,
+		WindowMinutes: rule.WindowMinutes,
+		Channel:       rule.Channel,
+		IsMuted:       rule.IsMuted,
+		CreatedAt:     rule.CreatedAt,
+	})
+}
+
+// Unmuting starts from now, so muted-time data doesn't fire alerts
+func (cfg *apiCfg) MuteAlertRule(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
+		return
+	}
+	var data AlertMuteReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	rows, err := cfg.db.SetAlertRuleMuted(c, database.SetAlertRuleMutedParams{
+		UserID:  user.ID,
+		ID:      id,
+		IsMuted: data.Muted,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) DeleteAlertRule(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
+		return
+	}
+	rows, err := cfg.db.DeleteAlertRuleByUserIdANDId(c, database.DeleteAlertRuleByUserIdANDIdParams{
+		UserID: user.ID,
+		ID:     id,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
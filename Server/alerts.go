@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// More than threshold clicks within the window
+	alertClicksAbove = "clicks_above"
+	// No clicks at all within the window
+	alertNoClicks = "no_clicks"
+	// A click from a country the link has never been clicked from before
+	alertNewCountry = "new_country"
+	// The link's total clicks reached the threshold; fires once
+	alertMaxClicks = "max_clicks"
+
+	alertChannelEmail   = "email"
+	alertChannelWebhook = "webhook"
+
+	alertCheckInterval = 5 * time.Minute
+)
+
+type AlertNotification struct {
+	RuleID      uuid.UUID `json:"rule_id"`
+	Type        string    `json:"type"`
+	Slug        string    `json:"slug"`
+	Message     string    `json:"message"`
+	Clicks      int64     `json:"clicks"`
+	Countries   []string  `json:"countries,omitempty"`
+	TriggeredAt time.Time `json:"triggered_at"`
+}
+
+// Delivers a triggered alert to the owner of the rule
+type AlertNotifier interface {
+	Notify(ctx context.Context, user database.User, alert AlertNotification) error
+}
+
+type EmailAlertNotifier struct {
+	mailer Mailer
+}
+
+func (n *EmailAlertNotifier) Notify(ctx context.Context, user database.User, alert AlertNotification) error {
+	body := fmt.Sprintf("Hi %s,\n\n%s\n\nYou can mute this alert from your dashboard.", user.Name, alert.Message)
+	return n.mailer.Send(user.Email, "Alert for /"+alert.Slug, body)
+}
+
+// Sends alerts through the webhook queue as alert.triggered
+type WebhookAlertNotifier struct {
+	cfg *apiCfg
+}
+
+func (n *WebhookAlertNotifier) Notify(ctx context.Context, user database.User, alert AlertNotification) error {
+	n.cfg.emitWebhookEvent(ctx, user.ID, webhookAlertTriggered, alert)
+	return nil
+}
+
+func newAlertNotifiers(cfg *apiCfg) map[string]AlertNotifier {
+	return map[string]AlertNotifier{
+		alertChannelEmail:   &EmailAlertNotifier{mailer: cfg.mailer},
+		alertChannelWebhook: &WebhookAlertNotifier{cfg: cfg},
+	}
+}
+
+type triggeredAlert struct {
+	channel string
+	user    database.User
+	alert   AlertNotification
+}
+
+// Rules are locked while evaluated; notifications go out after commit
+func (cfg *apiCfg) evaluateAlerts(ctx context.Context) error {
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := cfg.db.WithTx(tx)
+
+	// Click and link timestamps come from the database clock, so the windows do too
+	clock, err := q.GetAlertClock(ctx)
+	if err != nil {
+		return err
+	}
+	rules, err := q.LockActiveAlertRules(ctx)
+	if err != nil {
+		return err
+	}
+	now := clock.Now
+	var triggered []triggeredAlert
+	for _, rule := range rules {
+		alert, fired, err := evaluateAlertRule(ctx, q, rule, clock.RolledUpTo, now)
+		if err != nil {
+			return err
+		}
+		params := database.UpdateAlertRuleCheckParams{ID: rule.ID, CheckedUntil: now}
+		if fired {
+			params.TriggeredAt = sql.NullTime{Time: now, Valid: true}
+			triggered = append(triggered, triggeredAlert{
+				channel: rule.Channel,
+				user:    database.User{ID: rule.UserID, Name: rule.Name, Email: rule.Email},
+				alert:   alert,
+			})
+		}
+		if err := q.UpdateAlertRuleCheck(ctx, params); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, t := range triggered {
+		notifier, ok := cfg.alertNotifiers[t.channel]
+		if !ok {
+			log.Printf("Failed to send alert: unknown channel %q", t.channel)
+			continue
+		}
+		if err := notifier.Notify(ctx, t.user, t.alert); err != nil {
+			log.Printf("Failed to send alert: %v", err)
+		}
+	}
+	return nil
+}
+
+func evaluateAlertRule(ctx context.Context, q *database.Queries, rule database.LockActiveAlertRulesRow, rolledUpTo time.Time, now time.Time) (AlertNotification, bool, error) {
+	alert := AlertNotification{RuleID: rule.ID, Type: rule.Type, Slug: rule.Slug, TriggeredAt: now}
+	window := time.Duration(rule.WindowMinutes) * time.Minute
+	countSince := func(start time.Time) (int64, error) {
+		return q.CountLinkClicksBetween(ctx, database.CountLinkClicksBetweenParams{
+			ShortLinkID: rule.ShortLinkID,
+			StartTime:   start,
+			EndTime:     now,
+			RolledUpTo:  rolledUpTo,
+		})
+	}
+	// Window based rules fire at most once per window
+	firedWithin := func(d time.Duration) bool {
+		return rule.LastTriggeredAt.Valid && rule.LastTriggeredAt.Time.After(now.Add(-d))
+	}
+
+	switch rule.Type {
+	case alertClicksAbove:
+		if firedWithin(window) {
+			return alert, false, nil
+		}
+		clicks, err := countSince(now.Add(-window))
+		if err != nil || clicks <= rule.Threshold {
+			return alert, false, err
+		}
+		alert.Clicks = clicks
+		alert.Message = fmt.Sprintf("/%s got %d clicks in the last %s, above your limit of %d.", rule.Slug, clicks, formatAlertWindow(window), rule.Threshold)
+		return alert, true, nil
+	case alertNoClicks:
+		// A link younger than the window hasn't had the chance to be clicked yet
+		if firedWithin(window) || rule.LinkCreatedAt.After(now.Add(-window)) {
+			return alert, false, nil
+		}
+		clicks, err := countSince(now.Add(-window))
+		if err != nil || clicks > 0 {
+			return alert, false, err
+		}
+		alert.Message = fmt.Sprintf("/%s has not been clicked in the last %s.", rule.Slug, formatAlertWindow(window))
+		return alert, true, nil
+	case alertNewCountry:
+		countries, err := q.ListNewClickCountries(ctx, database.ListNewClickCountriesParams{
+			ShortLinkID: rule.ShortLinkID,
+			StartTime:   rule.CheckedUntil,
+			EndTime:     now,
+		})
+		if err != nil || len(countries) == 0 {
+			return alert, false, err
+		}
+		alert.Countries = countries
+		alert.Message = fmt.Sprintf("/%s got its first clicks from %s.", rule.Slug, strings.Join(countries, ", "))
+		return alert, true, nil
+	case alertMaxClicks:
+		if rule.LastTriggeredAt.Valid {
+			return alert, false, nil
+		}
+		clicks, err := countSince(time.Time{})
+		if err != nil || clicks < rule.Threshold {
+			return alert, false, err
+		}
+		alert.Clicks = clicks
+		alert.Message = fmt.Sprintf("/%s reached %d clicks.", rule.Slug, clicks)
+		return alert, true, nil
+	}
+	return alert, false, nil
+}
+
+func formatAlertWindow(window time.Duration) string {
+	if window%time.Hour == 0 {
+		if window == time.Hour {
+			return "hour"
+		}
+		return fmt.Sprintf("%d hours", int(window/time.Hour))
+	}
+	return fmt.Sprintf("%d minutes", int(window/time.Minute))
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 366
+	defaultTopRows       = 10
+	maxTopRows           = 50
+)
+
+var accountDimensions = []string{"country", "referrer", "source", "channel", "device_type", "platform"}
+
+// Reads ?from and ?to, or ?days back from now, as a half-open window
+func analyticsWindow(c *gin.Context, now time.Time) (time.Time, time.Time, error) {
+	to := now
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseListDate(value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be a date or RFC 3339 timestamp")
+		}
+		to = parsed.Time
+	}
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseListDate(value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be a date or RFC 3339 timestamp")
+		}
+		if !parsed.Time.Before(to) {
+			return time.Time{}, time.Time{}, errors.New("from must be before to")
+		}
+		if to.Sub(parsed.Time) > maxAnalyticsDays*24*time.Hour {
+			return time.Time{}, time.Time{}, errors.New("The period can be at most 366 days")
+		}
+		return parsed.Time, to, nil
+	}
+	days := defaultAnalyticsDays
+	if value := c.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxAnalyticsDays {
+			return time.Time{}, time.Time{}, errors.New("days must be between 1 and 366")
+		}
+		days = n
+	}
+	return to.AddDate(0, 0, -days), to, nil
+}
+
+// Relative change in percent, or nil when there is nothing to compare against
+func percentChange(current int64, previous int64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := float64(current-previous) / float64(previous) * 100
+	return &change
+}
+
+func (cfg *apiCfg) accountTotals(c *gin.Context, userID uuid.UUID, from time.Time, to time.Time, rolledUpTo time.Time) (AnalyticsTotals, error) {
+	totals, err := cfg.db.AccountClickTotals(c, database.AccountClickTotalsParams{
+		UserID:     userID,
+		StartTime:  from,
+		EndTime:    to,
+		RolledUpTo: rolledUpTo,
+	})
+	if err != nil {
+		return AnalyticsTotals{}, err
+	}
+	return AnalyticsTotals{
+		TotalClicks:  totals.TotalClicks,
+		UniqueClicks: totals.UniqueClicks,
+		ActiveLinks:  totals.ActiveLinks,
+	}, nil
+}
+
+// Daily totals for the window, with days without clicks filled in as zero
+func (cfg *apiCfg) accountTimeseries(c *gin.Context, userID uuid.UUID, from time.Time, to time.Time, rolledUpTo time.Time) ([]TimeseriesPoint, error) {
+	rows, err := cfg.db.AccountClicksByDay(c, database.AccountClicksByDayParams{
+		UserID:     userID,
+		StartTime:  from,
+		EndTime:    to,
+		RolledUpTo: rolledUpTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	byDay := map[string]database.AccountClicksByDayRow{}
+	for _, row := range rows {
+		byDay[row.Day.Format("2006-01-02")] = row
+	}
+	points := []TimeseriesPoint{}
+	for day := from.Truncate(24 * time.Hour); day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		row := byDay[date]
+		points = append(points, TimeseriesPoint{
+			Date:         date,
+			TotalClicks:  row.TotalClicks,
+			UniqueClicks: row.UniqueClicks,
+		})
+	}
+	return points, nil
+}
+
+// Totals across the user's links, compared with the window right before; widened to whole hours
+func (cfg *apiCfg) GetAccountAnalytics(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	from, to, err := analyticsWindow(c, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from = from.Truncate(time.Hour)
+	if !to.Equal(to.Truncate(time.Hour)) {
+		to = to.Truncate(time.Hour).Add(time.Hour)
+	}
+	topRows := defaultTopRows
+	if value := c.Query("top"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxTopRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "top must be between 1 and 50"})
+			return
+		}
+		topRows = n
+	}
+	previousFrom := from.Add(-to.Sub(from))
+	rolledUpTo, err := cfg.clickRollupWatermark(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	data := AccountAnalytics{
+		Period:         AnalyticsPeriod{From: from.Format(time.RFC3339), To: to.Format(time.RFC3339)},
+		PreviousPeriod: AnalyticsPeriod{From: previousFrom.Format(time.RFC3339), To: from.Format(time.RFC3339)},
+		TopLinks:       []TopLink{},
+	}
+	if data.Totals, err = cfg.accountTotals(c, user.ID, from, to, rolledUpTo); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if data.PreviousTotals, err = cfg.accountTotals(c, user.ID, previousFrom, from, rolledUpTo); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data.Change = AnalyticsChange{
+		TotalClicks:  percentChange(data.Totals.TotalClicks, data.PreviousTotals.TotalClicks),
+		UniqueClicks: percentChange(data.Totals.UniqueClicks, data.PreviousTotals.UniqueClicks),
+		ActiveLinks:  percentChange(data.Totals.ActiveLinks, data.PreviousTotals.ActiveLinks),
+	}
+	if data.Timeseries, err = cfg.accountTimeseries(c, user.ID, from, to, rolledUpTo); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	links, err := cfg.db.AccountTopLinks(c, database.AccountTopLinksParams{
+		UserID:     user.ID,
+		StartTime:  from,
+		EndTime:    to,
+		RolledUpTo: rolledUpTo,
+		RowLimit:   int32(topRows),
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	for _, link := range links {
+		data.TopLinks = append(data.TopLinks, TopLink{
+			Slug:         link.Slug,
+			OriginalURL:  link.OriginalUrl,
+			Title:        link.Title,
+			ShortURL:     cfg.frontendOrigin + link.Slug,
+			TotalClicks:  link.TotalClicks,
+			UniqueClicks: link.UniqueClicks,
+		})
+	}
+
+	for _, dimension := range accountDimensions {
+		rows, err := cfg.db.AccountTopDimension(c, database.AccountTopDimensionParams{
+			UserID:     user.ID,
+			Dimension:  dimension,
+			StartTime:  from,
+			EndTime:    to,
+			RolledUpTo: rolledUpTo,
+			RowLimit:   int32(topRows),
+		})
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		counts := []DimensionCount{}
+		for _, row := range rows {
+			counts = append(counts, DimensionCount{Value: row.Value, Clicks: row.TotalClicks})
+		}
+		switch dimension {
+		case "country":
+			data.TopCountries = counts
+		case "referrer":
+			data.TopReferrers = counts
+		case "source":
+			data.TopSources = counts
+		case "channel":
+			data.TopChannels = counts
+		case "device_type":
+			data.TopDeviceTypes = counts
+		case "platform":
+			data.TopPlatforms = counts
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	auditTargetUser   = "user"
+	auditTargetLink   = "link"
+	auditTargetReport = "abuse_report"
+
+	auditUserSuspend            = "user.suspend"
+	auditUserUnsuspend          = "user.unsuspend"
+	auditUserUpdate             = "user.update"
+	auditUserLogout             = "user.logout"
+	auditUserChangePassword     = "user.change_password"
+	auditUserRequestEmailChange = "user.request_email_change"
+	auditUserUpdatePrivacy      = "user.update_privacy"
+	auditLinkCreate             = "link.create"
+	auditLinkDelete             = "link.delete"
+	auditLinkRestore            = "link.restore"
+	auditLinkPurge              = "link.purge"
+	auditLinkToggle             = "link.toggle"
+	auditLinkUpdateUTM          = "link.update_utm"
+	auditLinkUpdateSlug         = "link.update_slug"
+	auditLinkUpdatePrivacy      = "link.update_privacy"
+	auditLinkBlock              = "link.block"
+	auditLinkUnblock            = "link.unblock"
+	auditLinkQuarantine         = "link.quarantine"
+	auditLinkRelease            = "link.release"
+	auditReportResolve          = "report.resolve"
+
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+	maxUserAgentLength   = 500
+)
+
+type auditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	// Who gets to see the entry; uuid.Nil keeps it to admins
+	AccountID uuid.UUID
+	// Only differing fields are stored; nil is stored as {}
+	Before any
+	After  any
+}
+
+// What the audit log keeps of a user
+func userAuditState(user database.User) gin.H {
+	return gin.H{
+		"name":              user.Name,
+		"role":              user.Role,
+		"suspended":         user.SuspendedAt.Valid,
+		"suspension_reason": user.SuspensionReason,
+		"privacy_mode":      user.PrivacyMode,
+	}
+}
+
+// What the audit log keeps of a link
+func linkAuditState(link database.ShortLink) gin.H {
+	return gin.H{
+		"slug":              link.Slug,
+		"original_url":      link.OriginalUrl,
+		"title":             link.Title,
+		"is_active":         link.IsActive.Bool,
+		"utm_source":        link.UtmSource,
+		"utm_medium":        link.UtmMedium,
+		"utm_campaign":      link.UtmCampaign,
+		"utm_term":          link.UtmTerm,
+		"utm_content":       link.UtmContent,
+		"extra_params":      decodeExtraParams(link.ExtraParams),
+		"utm_policy":        link.UtmPolicy,
+		"pass_query":        link.PassQuery,
+		"privacy_mode":      link.PrivacyMode,
+		"blocked":           link.BlockedAt.Valid,
+		"blocked_reason":    link.BlockedReason,
+		"quarantined":       link.QuarantinedAt.Valid,
+		"quarantine_reason": link.QuarantineReason,
+		"quarantined_by":    link.QuarantinedBy,
+		"deleted":           link.DeletedAt.Valid,
+	}
+}
+
+func auditJSON(state any) (json.RawMessage, error) {
+	if state == nil {
+		return json.RawMessage("{}"), nil
+	}
+	return json.Marshal(state)
+}
+
+// Drops the fields before and after agree on, leaving what the action changed
+func auditDiff(before, after any) (json.RawMessage, json.RawMessage, error) {
+	b, err := auditJSON(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := auditJSON(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if before == nil || after == nil {
+		return b, a, nil
+	}
+	var bFields, aFields map[string]json.RawMessage
+	if json.Unmarshal(b, &bFields) != nil || json.Unmarshal(a, &aFields) != nil {
+		// Not objects, so there are no fields to compare
+		return b, a, nil
+	}
+	for key, value := range bFields {
+		if other, ok := aFields[key]; ok && bytes.Equal(value, other) {
+			delete(bFields, key)
+			delete(aFields, key)
+		}
+	}
+	if b, err = json.Marshal(bFields); err != nil {
+		return nil, nil, err
+	}
+	if a, err = json.Marshal(aFields); err != nil {
+		return nil, nil, err
+	}
+	return b, a, nil
+}
+
+func newAuditLogEntry(entry auditEntry) (database.CreateAuditLogEntryParams, error) {
+	before, after, err := auditDiff(entry.Before, entry.After)
+	if err != nil {
+		return database.CreateAuditLogEntryParams{}, err
+	}
+	return database.CreateAuditLogEntryParams{
+		AccountID:  uuid.NullUUID{UUID: entry.AccountID, Valid: entry.AccountID != uuid.Nil},
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     before,
+		After:      after,
+	}, nil
+}
+
+// Writes through q, so inside a transaction it commits or rolls back with the change
+func writeAudit(c *gin.Context, q *database.Queries, entry auditEntry) error {
+	params, err := newAuditLogEntry(entry)
+	if err != nil {
+		return err
+	}
+	params.IpAddress = c.ClientIP()
+	params.UserAgent = c.Request.UserAgent()
+	if len(params.UserAgent) > maxUserAgentLength {
+		params.UserAgent = params.UserAgent[:maxUserAgentLength]
+	}
+	// Requests without a signed-in user are recorded without an actor
+	if val, ok := c.Get("currentUser"); ok {
+		if user, ok := val.(database.User); ok {
+			params.ActorID = uuid.NullUUID{UUID: user.ID, Valid: true}
+		}
+	}
+	return q.CreateAuditLogEntry(c, params)
+}
+
+// Records what a background job did, without an actor, address or user agent
+func writeJobAudit(ctx context.Context, q *database.Queries, entry auditEntry) error {
+	params, err := newAuditLogEntry(entry)
+	if err != nil {
+		return err
+	}
+	return q.CreateAuditLogEntry(ctx, params)
+}
+
+// Commits the change and its audit entry together
+func (cfg *apiCfg) auditedTx(c *gin.Context, fn func(q *database.Queries) (auditEntry, error)) error {
+	tx, err := cfg.conn.BeginTx(c, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := cfg.db.WithTx(tx)
+	entry, err := fn(q)
+	if err != nil {
+		return err
+	}
+	if err := writeAudit(c, q, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Reads limit and offset for the admin listings and the audit log
+func offsetPage(c *gin.Context) (int32, int32, error) {
+	limit, offset := defaultAuditPageSize, 0
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxAuditPageSize {
+			return 0, 0, errors.New("limit must be between 1 and 200")
+		}
+		limit = n
+	}
+	if value := c.Query("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("offset must be a non-negative number")
+		}
+		offset = n
+	}
+	return int32(limit), int32(offset), nil
+}
+
+// Reads ?action, target_type, target_id, created_after, created_before, limit and offset
+func auditListParams(c *gin.Context) (database.ListAuditLogParams, error) {
+	limit, offset, err := offsetPage(c)
+	if err != nil {
+		return database.ListAuditLogParams{}, err
+	}
+	params := database.ListAuditLogParams{
+		Action:     nullQuery(c, "action"),
+		TargetType: nullQuery(c, "target_type"),
+		TargetID:   nullQuery(c, "target_id"),
+		PageLimit:  limit,
+		PageOffset: offset,
+	}
+	if params.CreatedAfter, err = parseListDate(c.Query("created_after")); err != nil {
+		return params, errors.New("created_after must be a date or RFC 3339 timestamp")
+	}
+	if params.CreatedBefore, err = parseListDate(c.Query("created_before")); err != nil {
+		return params, errors.New("created_before must be a date or RFC 3339 timestamp")
+	}
+	return params, nil
+}
+
+func queryUUID(c *gin.Context, key string) (uuid.NullUUID, error) {
+	value := c.Query(key)
+	if value == "" {
+		return uuid.NullUUID{}, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.NullUUID{}, errors.New("Invalid " + key)
+	}
+	return uuid.NullUUID{UUID: id, Valid: true}, nil
+}
+
+func auditLogRes(entry database.AuditLog) AuditLogRes {
+	return AuditLogRes{
+		ID:         entry.ID,
+		ActorID:    uuidPtr(entry.ActorID),
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     entry.Before,
+		After:      entry.After,
+		IPAddress:  entry.IpAddress,
+		UserAgent:  entry.UserAgent,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+// ?slug narrows it to one link; an admin's address and device are hidden
+func (cfg *apiCfg) GetAuditLog(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	params, err := auditListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params.AccountID = uuid.NullUUID{UUID: user.ID, Valid: true}
+	if slug := c.Query("slug"); slug != "" {
+		// Links in the trash keep their history
+		link, err := cfg.db.RetrieveShortLinkBySlugNUserIdWithTrashed(c, database.RetrieveShortLinkBySlugNUserIdWithTrashedParams{
+			UserID: user.ID,
+			Slug:   slug,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+			return
+		}
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		params.TargetType = sql.NullString{String: auditTargetLink, Valid: true}
+		params.TargetID = sql.NullString{String: link.ID.String(), Valid: true}
+	}
+	entries, err := cfg.db.ListAuditLog(c, params)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data := []AuditLogRes{}
+	for _, entry := range entries {
+		res := auditLogRes(entry)
+		if !entry.ActorID.Valid || entry.ActorID.UUID != user.ID {
+			res.IPAddress = ""
+			res.UserAgent = ""
+		}
+		data = append(data, res)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// ?actor_id and account_id narrow it to one user
+func (cfg *apiCfg) AdminGetAuditLog(c *gin.Context) {
+	params, err := auditListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if params.ActorID, err = queryUUID(c, "actor_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if params.AccountID, err = queryUUID(c, "account_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entries, err := cfg.db.ListAuditLog(c, params)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data := []AuditLogRes{}
+	for _, entry := range entries {
+		data = append(data, auditLogRes(entry))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
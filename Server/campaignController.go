@@ -0,0 +1,318 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func campaignStatus(startsAt sql.NullTime, endsAt sql.NullTime, now time.Time) string {
+	switch {
+	case startsAt.Valid && now.Before(startsAt.Time):
+		return "scheduled"
+	case endsAt.Valid && !now.Before(endsAt.Time):
+		return "ended"
+	}
+	return "active"
+}
+
+func nullTimeString(t sql.NullTime) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := t.Time.String()
+	return &s
+}
+
+func campaignRes(campaign database.Campaign, linkCount int64) CampaignRes {
+	return CampaignRes{
+		ID:          campaign.ID,
+		Name:        campaign.Name,
+		UTMSource:   campaign.UtmSource,
+		UTMMedium:   campaign.UtmMedium,
+		UTMCampaign: campaign.UtmCampaign,
+		StartsAt:    nullTimeString(campaign.StartsAt),
+		EndsAt:      nullTimeString(campaign.EndsAt),
+		BudgetNote:  campaign.BudgetNote,
+		Status:      campaignStatus(campaign.StartsAt, campaign.EndsAt, time.Now().UTC()),
+		LinkCount:   linkCount,
+		CreatedAt:   campaign.CreatedAt.String(),
+	}
+}
+
+// Validates a campaign body; utm_campaign defaults to the campaign name
+func parseCampaignReq(data CampaignReq) (CampaignReq, sql.NullTime, sql.NullTime, error) {
+	data.Name = strings.TrimSpace(data.Name)
+	if err := validateLabel("Campaign name", data.Name, maxCampaignNameLength); err != nil {
+		return data, sql.NullTime{}, sql.NullTime{}, err
+	}
+	if len(data.BudgetNote) > maxBudgetNoteLength {
+		return data, sql.NullTime{}, sql.NullTime{}, errors.New("Budget note must be at most 1000 characters")
+	}
+	data.UTMCampaign = defaultString(strings.TrimSpace(data.UTMCampaign), data.Name)
+	startsAt, err := parseListDate(data.StartsAt)
+	if err != nil {
+		return data, sql.NullTime{}, sql.NullTime{}, errors.New("starts_at must be a date or RFC 3339 timestamp")
+	}
+	endsAt, err := parseListDate(data.EndsAt)
+	if err != nil {
+		return data, sql.NullTime{}, sql.NullTime{}, errors.New("ends_at must be a date or RFC 3339 timestamp")
+	}
+	if startsAt.Valid && endsAt.Valid && !endsAt.Time.After(startsAt.Time) {
+		return data, sql.NullTime{}, sql.NullTime{}, errors.New("ends_at must be after starts_at")
+	}
+	return data, startsAt, endsAt, nil
+}
+
+func campaignConflict(c *gin.Context, err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if pqErr.Code == "23505" { // Unique violation
+			c.JSON(http.StatusConflict, gin.H{"error": "Campaign already exists"})
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg *apiCfg) campaignFromParam(c *gin.Context, userID uuid.UUID) (database.Campaign, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "campaign not found"})
+		return database.Campaign{}, false
+	}
+	campaign, err := cfg.db.RetrieveCampaignByUserIdANDId(c, database.RetrieveCampaignByUserIdANDIdParams{
+		UserID: userID,
+		ID:     id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "campaign not found"})
+			return database.Campaign{}, false
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return database.Campaign{}, false
+	}
+	return campaign, true
+}
+
+func campaignListRes(campaigns []database.ListCampaignsByUserIdRow) []CampaignRes {
+	data := []CampaignRes{}
+	for _, row := range campaigns {
+		data = append(data, campaignRes(database.Campaign{
+			ID:          row.ID,
+			UserID:      row.UserID,
+			Name:        row.Name,
+			UtmSource:   row.UtmSource,
+			UtmMedium:   row.UtmMedium,
+			UtmCampaign: row.UtmCampaign,
+			StartsAt:    row.StartsAt,
+			EndsAt:      row.EndsAt,
+			BudgetNote:  row.BudgetNote,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		}, row.LinkCount))
+	}
+	return data
+}
+
+func (cfg *apiCfg) GetCampaigns(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	campaigns, err := cfg.db.ListCampaignsByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": campaignListRes(campaigns)})
+}
+
+func (cfg *apiCfg) GetCampaign(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	campaign, ok := cfg.campaignFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	linkCount, err := cfg.db.CountShortLinksByCampaignId(c, uuid.NullUUID{UUID: campaign.ID, Valid: true})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, campaignRes(campaign, linkCount))
+}
+
+func (cfg *apiCfg) CreateCampaign(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data CampaignReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	data, startsAt, endsAt, err := parseCampaignReq(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	campaign, err := cfg.db.CreateCampaign(c, database.CreateCampaignParams{
+		UserID:      user.ID,
+		Name:        data.Name,
+		UtmSource:   data.UTMSource,
+		UtmMedium:   data.UTMMedium,
+		UtmCampaign: data.UTMCampaign,
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		BudgetNote:  data.BudgetNote,
+	})
+	if err != nil {
+		if campaignConflict(c, err) {
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, campaignRes(campaign, 0))
+}
+
+// New UTM defaults only apply to links attached from now on
+func (cfg *apiCfg) UpdateCampaign(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	campaign, ok := cfg.campaignFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	var data CampaignReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	data, startsAt, endsAt, err := parseCampaignReq(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, err = cfg.db.UpdateCampaign(c, database.UpdateCampaignParams{
+		UserID:      user.ID,
+		ID:          campaign.ID,
+		Name:        data.Name,
+		UtmSource:   data.UTMSource,
+		UtmMedium:   data.UTMMedium,
+		UtmCampaign: data.UTMCampaign,
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		BudgetNote:  data.BudgetNote,
+	})
+	if err != nil {
+		if campaignConflict(c, err) {
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// Deletes the campaign; its links stay and are simply detached
+func (cfg *apiCfg) DeleteCampaign(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	campaign, ok := cfg.campaignFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	_, err := cfg.db.DeleteCampaignByUserIdANDId(c, database.DeleteCampaignByUserIdANDIdParams{
+		UserID: user.ID,
+		ID:     campaign.ID,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) GetCampaignAnalytics(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	campaign, ok := cfg.campaignFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	rolledUpTo, err := cfg.clickRollupWatermark(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	rollups, err := cfg.db.DailyRollupsByCampaign(c, database.DailyRollupsByCampaignParams{
+		CampaignID: uuid.NullUUID{UUID: campaign.ID, Valid: true},
+		Bucket:     rolledUpTo,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	rows, err := cfg.db.AnalyticsRetrievalByCampaign(c, database.AnalyticsRetrievalByCampaignParams{
+		CampaignID: uuid.NullUUID{UUID: campaign.ID, Valid: true},
+		CreatedAt:  rolledUpTo,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	analyticsData := make([]database.AnalyticsRetrievalRow, 0, len(rows))
+	for _, row := range rows {
+		analyticsData = append(analyticsData, database.AnalyticsRetrievalRow(row))
+	}
+	data := newAnalytics()
+	addRollupData(&data, rollups)
+	sortAnalyticsData(&data, analyticsData)
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// Fills in UTM values the links don't set from the campaign; a null campaign_id detaches them
+func (cfg *apiCfg) AttachLinksToCampaign(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data LinkCampaignReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if len(data.Slugs) == 0 || len(data.Slugs) > maxBulkLinks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slugs must contain between 1 and 1000 links"})
+		return
+	}
+	params := database.AttachShortLinksToCampaignParams{
+		CampaignID: nullUUIDFromPtr(data.CampaignID),
+		UserID:     user.ID,
+		Slugs:      data.Slugs,
+	}
+	if data.CampaignID != nil {
+		campaign, err := cfg.db.RetrieveCampaignByUserIdANDId(c, database.RetrieveCampaignByUserIdANDIdParams{
+			UserID: user.ID,
+			ID:     *data.CampaignID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "campaign not found"})
+				return
+			}
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		params.UtmSource = campaign.UtmSource
+		params.UtmMedium = campaign.UtmMedium
+		params.UtmCampaign = campaign.UtmCampaign
+	}
+	links, err := cfg.db.AttachShortLinksToCampaign(c, params)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	for _, link := range links {
+		cfg.emitLinkEvent(c, webhookLinkUpdated, link)
+	}
+	c.JSON(http.StatusOK, BulkUpdateRes{Success: true, Updated: int64(len(links))})
+}
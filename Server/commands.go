@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+)
+
+const commandUsage = `usage:
+  prune-clicks                          roll up pending clicks and apply the retention policy now
+  reclassify-referrers                  recompute the referrer domain, source and channel of stored clicks
+  set-retention <email> <days|default>  override the click retention period of one account
+  set-admin <email> <on|off>            grant or revoke access to the admin API`
+
+// Runs a one-off maintenance command instead of starting the server
+func (cfg *apiCfg) runCommand(args []string) error {
+	ctx := context.Background()
+	switch args[0] {
+	case "prune-clicks":
+		if err := cfg.rollUpClicks(ctx); err != nil {
+			return err
+		}
+		pruned, err := cfg.pruneClicks(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %s retention to %d clicks\n", cfg.retention.mode, pruned)
+		return nil
+	case "reclassify-referrers":
+		changed, err := cfg.reclassifyReferrers(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reclassified %d clicks\n", changed)
+		return nil
+	case "set-retention":
+		if len(args) != 3 {
+			return errors.New(commandUsage)
+		}
+		days := sql.NullInt32{}
+		if args[2] != "default" {
+			n, err := strconv.Atoi(args[2])
+			if err != nil || n < 1 || n > maxClickRetentionDays {
+				return errors.New("days must be between 1 and 3650, or default")
+			}
+			days = sql.NullInt32{Int32: int32(n), Valid: true}
+		}
+		user, err := cfg.db.RetrieveUserByEmail(ctx, args[1])
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("no account with email %s", args[1])
+			}
+			return err
+		}
+		if _, err := cfg.db.SetUserClickRetention(ctx, database.SetUserClickRetentionParams{
+			ID:                 user.ID,
+			ClickRetentionDays: days,
+		}); err != nil {
+			return err
+		}
+		fmt.Printf("Click retention for %s set to %s\n", user.Email, args[2])
+		return nil
+	case "set-admin":
+		if len(args) != 3 || (args[2] != "on" && args[2] != "off") {
+			return errors.New(commandUsage)
+		}
+		role := roleUser
+		if args[2] == "on" {
+			role = roleAdmin
+		}
+		rows, err := cfg.db.SetUserRole(ctx, database.SetUserRoleParams{
+			Email: args[1],
+			Role:  role,
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return fmt.Errorf("no account with email %s", args[1])
+		}
+		fmt.Printf("%s is now %s\n", args[1], role)
+		return nil
+	}
+	return errors.New(commandUsage)
+}
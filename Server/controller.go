@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/HarmanPreet-Singh-XYT/internal/database"
@@ -24,6 +26,19 @@ func (cfg *apiCfg) registerUser(c *gin.Context) {
 		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
 		return
 	}
+	data.Name = strings.TrimSpace(data.Name)
+	if err := validateName(data.Name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateEmail(data.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := cfg.validatePassword(data.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
 	if err != nil {
 		c.AbortWithError(http.StatusBadRequest, err)
@@ -63,6 +78,9 @@ func (cfg *apiCfg) registerUser(c *gin.Context) {
 		c.AbortWithError(http.StatusBadRequest, err)
 		return
 	}
+	if err := cfg.sendVerificationEmail(c, userCreation); err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+	}
 	c.JSON(http.StatusOK, AuthRes{
 		RefreshToken: refreshToken,
 		AccessToken:  accessToken,
@@ -77,63 +95,52 @@ func (cfg *apiCfg) loginUser(c *gin.Context) {
 		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
 		return
 	}
-	userInfo, err := cfg.db.RetrieveUserByEmail(c, data.Email)
+	wait, err := cfg.loginRetryAfter(c, data.Email)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			// User not found
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		// Some other DB error
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
-	errPass := bcrypt.CompareHashAndPassword([]byte(userInfo.Password), []byte(data.Password))
-	if errPass != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Password does not match"})
+	if wait > 0 {
+		abortLoginRetryAfter(c, wait)
 		return
 	}
-	refreshToken, err := createToken(userInfo.ID, time.Duration(time.Hour*24*7), cfg.jwtRefreshSecret)
-	if err != nil {
-		c.AbortWithError(http.StatusBadRequest, err)
+	userInfo, err := cfg.db.RetrieveUserByEmail(c, data.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
-
-	errToken := cfg.db.CreateToken(c, database.CreateTokenParams{
-		UserID:       userInfo.ID,
-		RefreshToken: refreshToken,
-	})
-	if errToken != nil {
-		var pqErr *pq.Error
-		if errors.As(errToken, &pqErr) {
-			if pqErr.Code == "23505" { // Unique violation
-				// If no token found, create one
-				errToken = cfg.db.UpdateTokenByUserId(c, database.UpdateTokenByUserIdParams{
-					UserID:       userInfo.ID,
-					RefreshToken: refreshToken,
-				})
-				if errToken != nil {
-					c.AbortWithError(http.StatusBadRequest, errToken)
-					return
-				}
-			}
-		} else {
-			c.AbortWithError(http.StatusInternalServerError, errToken)
+	// Compare against a dummy hash for unknown emails so both cases take the same time
+	passwordHash := dummyPasswordHash
+	if err == nil {
+		passwordHash = []byte(userInfo.Password)
+	}
+	errPass := bcrypt.CompareHashAndPassword(passwordHash, []byte(data.Password))
+	if err != nil || errPass != nil {
+		cfg.recordLoginAttempt(c, userInfo.ID, data.Email, false)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+	if userInfo.MfaEnabled {
+		// The login is only recorded as successful once the second factor checks out
+		challengeToken, err := createMfaChallengeToken(userInfo.ID, cfg.jwtSecret)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
 			return
 		}
+		c.JSON(http.StatusOK, MfaChallengeRes{Status: "mfa_required", ChallengeToken: challengeToken})
+		return
+	}
+	cfg.recordLoginAttempt(c, userInfo.ID, data.Email, true)
+	session, err := cfg.startSession(c, userInfo)
+	if errors.Is(err, errAccountSuspended) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
 	}
-	accessToken, err := createToken(userInfo.ID, time.Duration(time.Minute*15), cfg.jwtSecret)
 	if err != nil {
-		c.AbortWithError(http.StatusBadRequest, err)
+		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
-	c.JSON(http.StatusOK, AuthRes{
-		RefreshToken: refreshToken,
-		AccessToken:  accessToken,
-		Name:         userInfo.Name,
-		Email:        userInfo.Email,
-		Id:           userInfo.ID,
-	})
+	c.JSON(http.StatusOK, session)
 }
 func (cfg *apiCfg) renewToken(c *gin.Context) {
 	var data TokenReq
@@ -186,6 +193,10 @@ func (cfg *apiCfg) renewToken(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	if user.SuspendedAt.Valid {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errAccountSuspended.Error()})
+		return
+	}
 
 	refreshToken, err := createToken(user.ID, 7*24*time.Hour, cfg.jwtRefreshSecret)
 	if err != nil {
@@ -216,64 +227,94 @@ func (cfg *apiCfg) renewToken(c *gin.Context) {
 func (cfg *apiCfg) profileInfo(c *gin.Context) {
 	user := sortMiddlewareAuth(c)
 	c.JSON(http.StatusOK, UserInfoReq{
-		Id:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
+		Id:                   user.ID,
+		Name:                 user.Name,
+		Email:                user.Email,
+		EmailVerified:        user.EmailVerified,
+		MfaEnabled:           user.MfaEnabled,
+		CreatedAt:            user.CreatedAt,
+		DeletionScheduledFor: nullTimePtr(user.DeletionScheduledAt),
+		PrivacyMode:          user.PrivacyMode,
 	})
 }
 
-func (cfg *apiCfg) GetLinks(c *gin.Context) {
-	// Debug: Check if user authentication works
+func (cfg *apiCfg) GetSignIns(c *gin.Context) {
 	user := sortMiddlewareAuth(c)
-
-	var outputData []Link
-
-	// Retrieve short links for the user
-	data, err := cfg.db.RetrieveShortLinkByUserId(c, user.ID)
+	attempts, err := cfg.db.RetrieveLoginAttemptsByUserId(c, database.RetrieveLoginAttemptsByUserIdParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Limit:  recentSignInsLimit,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve links"})
+		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	outputData := []SignInRes{}
+	for _, val := range attempts {
+		outputData = append(outputData, SignInRes{
+			IPAddress: val.IpAddress,
+			UserAgent: val.UserAgent,
+			Success:   val.Success,
+			CreatedAt: val.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": outputData})
+}
 
-	// If no links found, return empty array instead of null
-	if len(data) == 0 {
-		c.JSON(http.StatusOK, []Link{})
+func (cfg *apiCfg) GetLinks(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	params, err := linkListParams(c, user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	pageSize := int(params.PageLimit)
+	// One extra row tells us whether there is a next page
+	params.PageLimit++
+	data, err := cfg.db.ListShortLinksWithStats(c, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve links"})
+		return
+	}
+	var nextCursor *string
+	if len(data) > pageSize {
+		data = data[:pageSize]
+		cursor := encodeLinkCursor(data[pageSize-1])
+		nextCursor = &cursor
+	}
 
-	// Process each link
+	outputData := []Link{}
 	for _, val := range data {
-
-		totalClicks, err := cfg.db.CountTotalClickByShortLinkId(c, val.ID)
-		if err != nil {
-			fmt.Printf("Debug: Error counting total clicks: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count total clicks"})
-			return
+		link := Link{
+			Slug:             val.Slug,
+			OriginalURL:      val.OriginalUrl,
+			Title:            val.Title,
+			CreatedAt:        val.CreatedAt.String(),
+			TotalClicks:      int(val.TotalClicks),
+			UniqueClicks:     int(val.UniqueClicks),
+			UTMSource:        val.UtmSource,
+			UTMMedium:        val.UtmMedium,
+			UTMCampaign:      val.UtmCampaign,
+			IsActive:         val.IsActive.Bool,
+			UpdatedAt:        val.UpdatedAt.Time.String(),
+			ShortURL:         cfg.frontendOrigin + val.Slug,
+			Tags:             val.Tags,
+			FolderID:         uuidPtr(val.FolderID),
+			CampaignID:       uuidPtr(val.CampaignID),
+			PrivacyMode:      val.PrivacyMode,
+			HealthStatus:     val.HealthStatus,
+			HealthCheckedAt:  nullTimePtr(val.HealthCheckedAt),
+			IsQuarantined:    val.QuarantinedAt.Valid,
+			QuarantineReason: val.QuarantineReason,
+			IsBlocked:        val.BlockedAt.Valid,
+			BlockedReason:    val.BlockedReason,
 		}
-
-		uniqueClicks, err := cfg.db.CountUniqueClickByShortLinkId(c, val.ID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unique clicks"})
-			return
+		if val.HealthStatusCode.Valid {
+			link.HealthStatusCode = &val.HealthStatusCode.Int32
 		}
-
-		outputData = append(outputData, Link{
-			Slug:         val.Slug,
-			OriginalURL:  val.OriginalUrl,
-			CreatedAt:    val.CreatedAt.String(),
-			TotalClicks:  int(totalClicks),
-			UniqueClicks: int(uniqueClicks),
-			UTMSource:    val.UtmSource,
-			UTMMedium:    val.UtmMedium,
-			UTMCampaign:  val.UtmCampaign,
-			IsActive:     val.IsActive.Bool,
-			UpdatedAt:    val.UpdatedAt.Time.String(),
-			ShortURL:     cfg.frontendOrigin + val.Slug,
-		})
+		outputData = append(outputData, link)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": outputData})
+	c.JSON(http.StatusOK, gin.H{"data": outputData, "next_cursor": nextCursor})
 }
 
 func (cfg *apiCfg) shortenLink(c *gin.Context) {
@@ -286,13 +327,79 @@ func (cfg *apiCfg) shortenLink(c *gin.Context) {
 	if data.Slug == "" {
 		data.Slug = GenerateRandomString(6)
 	}
-	err := cfg.db.CreateShortLink(c, database.CreateShortLinkParams{
-		UserID:      user.ID,
-		Slug:        data.Slug,
-		OriginalUrl: data.URL,
-		UtmSource:   data.UTMSource,
-		UtmMedium:   data.UTMMedium,
-		UtmCampaign: data.UTMCampaign,
+	if err := cfg.checkSlugQuarantine(c, data.Slug); err != nil {
+		if errors.Is(err, errSlugQuarantined) {
+			c.JSON(http.StatusConflict, gin.H{"error": "slug already exists"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data.UTMPolicy = defaultString(data.UTMPolicy, utmPolicyOverride)
+	if err := validateUTMPolicy(data.UTMPolicy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateExtraParams(data.ExtraParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validatePrivacyMode(data.PrivacyMode, true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	extraParams, err := encodeExtraParams(data.ExtraParams)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if data.CampaignID != nil {
+		campaign, err := cfg.db.RetrieveCampaignByUserIdANDId(c, database.RetrieveCampaignByUserIdANDIdParams{
+			UserID: user.ID,
+			ID:     *data.CampaignID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "campaign not found"})
+				return
+			}
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		// UTM values left blank on the link fall back to the campaign defaults
+		data.UTMSource = defaultString(data.UTMSource, campaign.UtmSource)
+		data.UTMMedium = defaultString(data.UTMMedium, campaign.UtmMedium)
+		data.UTMCampaign = defaultString(data.UTMCampaign, campaign.UtmCampaign)
+	}
+	var link database.ShortLink
+	err = cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		var err error
+		link, err = q.CreateShortLink(c, database.CreateShortLinkParams{
+			UserID:      user.ID,
+			Slug:        data.Slug,
+			OriginalUrl: data.URL,
+			UtmSource:   data.UTMSource,
+			UtmMedium:   data.UTMMedium,
+			UtmCampaign: data.UTMCampaign,
+			Title:       strings.TrimSpace(data.Title),
+			CampaignID:  nullUUIDFromPtr(data.CampaignID),
+			UtmTerm:     data.UTMTerm,
+			UtmContent:  data.UTMContent,
+			ExtraParams: extraParams,
+			UtmPolicy:   data.UTMPolicy,
+			PassQuery:   data.PassQuery,
+			PrivacyMode: data.PrivacyMode,
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     auditLinkCreate,
+			TargetType: auditTargetLink,
+			TargetID:   link.ID.String(),
+			AccountID:  user.ID,
+			After:      linkAuditState(link),
+		}, nil
 	})
 	if err != nil {
 		var pqErr *pq.Error
@@ -305,8 +412,10 @@ func (cfg *apiCfg) shortenLink(c *gin.Context) {
 		c.AbortWithError(http.StatusBadRequest, err)
 		return
 	}
+	link = cfg.screenLinkOnSave(c, link)
+	cfg.emitLinkEvent(c, webhookLinkCreated, link)
 
-	c.JSON(http.StatusOK, gin.H{"short_url": cfg.frontendOrigin + data.Slug})
+	c.JSON(http.StatusOK, gin.H{"short_url": cfg.frontendOrigin + data.Slug, "is_quarantined": link.QuarantinedAt.Valid})
 }
 func (cfg *apiCfg) GetLink(c *gin.Context) {
 	user := sortMiddlewareAuth(c)
@@ -330,12 +439,21 @@ func (cfg *apiCfg) GetLink(c *gin.Context) {
 		return
 	}
 	c.JSON(http.StatusOK, LinkReq{
-		URL:         slugData.OriginalUrl,
-		UTMSource:   slugData.UtmSource,
-		UTMMedium:   slugData.UtmMedium,
-		UTMCampaign: slugData.UtmCampaign,
-		Slug:        slugData.Slug,
-		CreatedAt:   slugData.CreatedAt.String(),
+		URL:               slugData.OriginalUrl,
+		UTMSource:         slugData.UtmSource,
+		UTMMedium:         slugData.UtmMedium,
+		UTMCampaign:       slugData.UtmCampaign,
+		UTMTerm:           slugData.UtmTerm,
+		UTMContent:        slugData.UtmContent,
+		ExtraParams:       decodeExtraParams(slugData.ExtraParams),
+		UTMPolicy:         slugData.UtmPolicy,
+		PassQuery:         slugData.PassQuery,
+		Title:             slugData.Title,
+		Slug:              slugData.Slug,
+		CreatedAt:         slugData.CreatedAt.String(),
+		PrivacyMode:       slugData.PrivacyMode,
+		HealthAction:      slugData.HealthAction,
+		HealthFallbackURL: slugData.HealthFallbackUrl,
 	})
 }
 func (cfg *apiCfg) DeleteLink(c *gin.Context) {
@@ -345,9 +463,26 @@ func (cfg *apiCfg) DeleteLink(c *gin.Context) {
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
-	err := cfg.db.DeleteShortLinkBySlugNUserId(c, database.DeleteShortLinkBySlugNUserIdParams{
-		UserID: user.ID,
-		Slug:   slug,
+	var link database.ShortLink
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		var err error
+		link, err = q.TrashShortLinkBySlugNUserId(c, database.TrashShortLinkBySlugNUserIdParams{
+			UserID: user.ID,
+			Slug:   slug,
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		live := link
+		live.DeletedAt = sql.NullTime{}
+		return auditEntry{
+			Action:     auditLinkDelete,
+			TargetType: auditTargetLink,
+			TargetID:   link.ID.String(),
+			AccountID:  user.ID,
+			Before:     linkAuditState(live),
+			After:      linkAuditState(link),
+		}, nil
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -359,6 +494,7 @@ func (cfg *apiCfg) DeleteLink(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	cfg.emitLinkEvent(c, webhookLinkDeleted, link)
 	c.JSON(http.StatusOK, SuccessRes{Success: true})
 }
 
@@ -383,28 +519,28 @@ func (cfg *apiCfg) GetAnalytics(c *gin.Context) {
 		return
 	}
 
-	data := Analytics{
-		TotalClicks:  0,
-		UniqueClicks: 0,
-		ByCountry:    map[string]int{},
-		ByReferrer:   map[string]int{},
-		UTMBreakdown: UTMB{
-			UTMSource:   map[string]int{},
-			UTMMedium:   map[string]int{},
-			UTMCampaign: map[string]int{},
-		},
-		ClicksByDate: map[string]int{},
-		DeviceSummary: DeviceAnalytics{
-			DeviceType:       map[string]int{},
-			Platform:         map[string]int{},
-			Language:         map[string]int{},
-			ScreenResolution: map[string]int{},
-			Timezone:         map[string]int{},
-			UserAgents:       map[string]int{},
-		},
+	data := newAnalytics()
+
+	// Whole hours come from the rollups, the clicks since then from the raw tables
+	rolledUpTo, err := cfg.clickRollupWatermark(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	rollups, err := cfg.db.DailyRollupsByShortLinkId(c, database.DailyRollupsByShortLinkIdParams{
+		ShortLinkID: slugData.ID,
+		Bucket:      rolledUpTo,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
 	}
+	addRollupData(&data, rollups)
 
-	analyticsData, err := cfg.db.AnalyticsRetrieval(c, slugData.ID)
+	analyticsData, err := cfg.db.AnalyticsRetrieval(c, database.AnalyticsRetrievalParams{
+		ShortLinkID: slugData.ID,
+		CreatedAt:   rolledUpTo,
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusOK, gin.H{"data": data})
@@ -426,43 +562,148 @@ func (cfg *apiCfg) ProfileUpdate(c *gin.Context) {
 		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
 		return
 	}
-	if data.ResourceType == "email" {
-		err := cfg.db.UpdateUserEmail(c, database.UpdateUserEmailParams{ID: user.ID, Email: data.Value})
-		if err != nil {
-			var pqErr *pq.Error
-			if errors.As(err, &pqErr) {
-				if pqErr.Code == "23505" { // Unique violation
-					c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
-					return
-				}
-			}
-			c.AbortWithError(http.StatusBadRequest, err)
+	switch data.ResourceType {
+	case "name":
+		name := strings.TrimSpace(data.Value)
+		if err := validateName(name); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-	} else if data.ResourceType == "name" {
-		err := cfg.db.UpdateUserName(c, database.UpdateUserNameParams{
-			ID:   user.ID,
-			Name: data.Value,
+		err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+			if err := q.UpdateUserName(c, database.UpdateUserNameParams{
+				ID:   user.ID,
+				Name: name,
+			}); err != nil {
+				return auditEntry{}, err
+			}
+			updated := user
+			updated.Name = name
+			return auditEntry{
+				Action:     auditUserUpdate,
+				TargetType: auditTargetUser,
+				TargetID:   user.ID.String(),
+				AccountID:  user.ID,
+				Before:     userAuditState(user),
+				After:      userAuditState(updated),
+			}, nil
 		})
 		if err != nil {
 			c.AbortWithError(http.StatusInternalServerError, err)
 			return
 		}
-	} else if data.ResourceType == "password" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(data.Value), bcrypt.DefaultCost)
-		if err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
-			return
-		}
-		errDB := cfg.db.UpdateUserPassword(c, database.UpdateUserPasswordParams{ID: user.ID, Password: string(hashedPassword)})
-		if errDB != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
-			return
-		}
+	case "email":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use PATCH /user/email to change the email address"})
+		return
+	case "password":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use PATCH /user/password to change the password"})
+		return
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown profile field"})
+		return
 	}
 
 	c.JSON(http.StatusOK, SuccessRes{Success: true})
 }
+func (cfg *apiCfg) ChangePassword(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data ChangePasswordReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(data.CurrentPassword)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password does not match"})
+		return
+	}
+	if err := cfg.validatePassword(data.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(data.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	err = cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		// Updating the password also invalidates every access token issued before now
+		if err := q.UpdateUserPassword(c, database.UpdateUserPasswordParams{ID: user.ID, Password: string(hashedPassword)}); err != nil {
+			return auditEntry{}, err
+		}
+		if err := q.DeleteToken(c, user.ID); err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     auditUserChangePassword,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID.String(),
+			AccountID:  user.ID,
+		}, nil
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	// Hand the caller a fresh session so only the other devices get signed out
+	session, err := cfg.startSession(c, user)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, AuthTokenRes{
+		RefreshToken: session.RefreshToken,
+		AccessToken:  session.AccessToken,
+	})
+}
+func (cfg *apiCfg) ChangeEmail(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data ChangeEmailReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if err := validateEmail(data.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// The new address only takes effect once confirmed through the link sent to it
+	_, err := cfg.db.RetrieveUserByEmail(c, data.Email)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	var token string
+	err = cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		var err error
+		token, err = issueEmailToken(c, q, user.ID, emailTokenChange, data.Email, changeTokenExpiry)
+		if err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     auditUserRequestEmailChange,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID.String(),
+			AccountID:  user.ID,
+			After:      gin.H{"email": data.Email},
+		}, nil
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err := cfg.sendEmailChangeConfirmation(user, data.Email, token); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	notice := fmt.Sprintf("Hi %s,\n\nA change of your account email to %s was requested. If this wasn't you, reset your password right away.", user.Name, data.Email)
+	if err := cfg.mailer.Send(user.Email, "Email change requested", notice); err != nil {
+		log.Printf("Failed to send email change notice: %v", err)
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Confirmation sent to the new email address"})
+}
 func (cfg *apiCfg) ToggleLink(c *gin.Context) {
 	user := sortMiddlewareAuth(c)
 	slug := c.Param("slug")
@@ -470,9 +711,26 @@ func (cfg *apiCfg) ToggleLink(c *gin.Context) {
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
-	err := cfg.db.ToggleShortLink(c, database.ToggleShortLinkParams{
-		Slug:   slug,
-		UserID: user.ID,
+	var link database.ShortLink
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		var err error
+		link, err = q.ToggleShortLink(c, database.ToggleShortLinkParams{
+			Slug:   slug,
+			UserID: user.ID,
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		before := link
+		before.IsActive.Bool = !link.IsActive.Bool
+		return auditEntry{
+			Action:     auditLinkToggle,
+			TargetType: auditTargetLink,
+			TargetID:   link.ID.String(),
+			AccountID:  user.ID,
+			Before:     linkAuditState(before),
+			After:      linkAuditState(link),
+		}, nil
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -484,6 +742,7 @@ func (cfg *apiCfg) ToggleLink(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	cfg.emitLinkEvent(c, webhookLinkToggled, link)
 	c.JSON(http.StatusOK, SuccessRes{Success: true})
 }
 func (cfg *apiCfg) UpdateUTM(c *gin.Context) {
@@ -493,28 +752,95 @@ func (cfg *apiCfg) UpdateUTM(c *gin.Context) {
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
-	var data UTMReq
+	var data LinkUTMReq
 	if err := c.ShouldBindJSON(&data); err != nil {
 		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
 		return
 	}
-	err := cfg.db.UpdateShortLinkUTM(c, database.UpdateShortLinkUTMParams{
+	link, err := cfg.db.RetrieveShortLinkBySlugNUserId(c, database.RetrieveShortLinkBySlugNUserIdParams{
+		UserID: user.ID,
+		Slug:   slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	params := database.UpdateShortLinkUTMParams{
 		Slug:        slug,
 		UtmSource:   data.UTMSource,
 		UtmMedium:   data.UTMMedium,
 		UtmCampaign: data.UTMCampaign,
 		UserID:      user.ID,
-	})
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			// User not found
-			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		UtmTerm:     link.UtmTerm,
+		UtmContent:  link.UtmContent,
+		ExtraParams: link.ExtraParams,
+		UtmPolicy:   link.UtmPolicy,
+		PassQuery:   link.PassQuery,
+	}
+	if data.UTMTerm != nil {
+		params.UtmTerm = *data.UTMTerm
+	}
+	if data.UTMContent != nil {
+		params.UtmContent = *data.UTMContent
+	}
+	if data.ExtraParams != nil {
+		if err := validateExtraParams(data.ExtraParams); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		// Some other DB error
+		params.ExtraParams, err = encodeExtraParams(data.ExtraParams)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if data.UTMPolicy != nil {
+		if err := validateUTMPolicy(*data.UTMPolicy); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		params.UtmPolicy = *data.UTMPolicy
+	}
+	if data.PassQuery != nil {
+		params.PassQuery = *data.PassQuery
+	}
+	var updated database.ShortLink
+	err = cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		if err := q.UpdateShortLinkUTM(c, params); err != nil {
+			return auditEntry{}, err
+		}
+		var err error
+		updated, err = q.RetrieveShortLinkBySlugNUserId(c, database.RetrieveShortLinkBySlugNUserIdParams{
+			UserID: user.ID,
+			Slug:   slug,
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     auditLinkUpdateUTM,
+			TargetType: auditTargetLink,
+			TargetID:   link.ID.String(),
+			AccountID:  user.ID,
+			Before:     linkAuditState(link),
+			After:      linkAuditState(updated),
+		}, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		return
+	}
+	if err != nil {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	cfg.emitLinkUpdated(c, user.ID, slug)
+	// The appended parameters change the destination, so it is scanned again
+	cfg.screenLinkOnSave(c, updated)
 	c.JSON(http.StatusOK, SuccessRes{Success: true})
 }
 func (cfg *apiCfg) UpdateSlug(c *gin.Context) {
@@ -529,13 +855,42 @@ func (cfg *apiCfg) UpdateSlug(c *gin.Context) {
 		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
 		return
 	}
-	err := cfg.db.UpdateShortLinkSlug(c, database.UpdateShortLinkSlugParams{Slug: slug, UserID: user.ID})
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			// User not found
-			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+	if data.Slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slug is required"})
+		return
+	}
+	if err := cfg.checkSlugQuarantine(c, data.Slug); err != nil {
+		if errors.Is(err, errSlugQuarantined) {
+			c.JSON(http.StatusConflict, gin.H{"error": "link already exists"})
 			return
 		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	var renamed database.ShortLink
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		var err error
+		renamed, err = q.UpdateShortLinkSlug(c, database.UpdateShortLinkSlugParams{Slug: slug, UserID: user.ID, Slug_2: data.Slug})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		// Only the slug changed, so the row is otherwise the state before
+		link := renamed
+		link.Slug = slug
+		return auditEntry{
+			Action:     auditLinkUpdateSlug,
+			TargetType: auditTargetLink,
+			TargetID:   renamed.ID.String(),
+			AccountID:  user.ID,
+			Before:     linkAuditState(link),
+			After:      linkAuditState(renamed),
+		}, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		return
+	}
+	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
 			if pqErr.Code == "23505" { // Unique violation
@@ -547,11 +902,22 @@ func (cfg *apiCfg) UpdateSlug(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	cfg.emitLinkEvent(c, webhookLinkUpdated, renamed)
 	c.JSON(http.StatusOK, SuccessRes{Success: true})
 }
 func (cfg *apiCfg) LogoutUser(c *gin.Context) {
 	user := sortMiddlewareAuth(c)
-	err := cfg.db.DeleteToken(c, user.ID)
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		if err := q.DeleteToken(c, user.ID); err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     auditUserLogout,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID.String(),
+			AccountID:  user.ID,
+		}, nil
+	})
 	if err != nil {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
@@ -569,20 +935,20 @@ func (cfg *apiCfg) RedirectLink(c *gin.Context) {
 		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
 		return
 	}
-	linkData, err := cfg.db.RetrieveShortLinkBySlug(c, slug)
+	linkData, kind, scheduleFallback, err := cfg.linkState(c, slug)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			// User not found
-			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
-			return
-		}
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
-	if !linkData.IsActive.Bool {
-		c.JSON(http.StatusMethodNotAllowed, RedirectResponse{OriginalURL: ""})
+	if kind != "" {
+		cfg.fallbackJSON(c, linkData, kind, scheduleFallback)
+		return
+	}
+	destination, err := buildDestinationURL(linkData, data.Query)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
-	c.JSON(http.StatusOK, RedirectResponse{OriginalURL: linkData.OriginalUrl})
-	go cfg.SaveAnalytics(c, linkData.ID, data)
+	c.JSON(http.StatusOK, RedirectResponse{OriginalURL: destination})
+	go cfg.SaveAnalytics(c.Copy(), linkData, data)
 }
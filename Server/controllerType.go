@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/HarmanPreet-Singh-XYT/internal/database"
@@ -17,11 +18,19 @@ type LoginReq struct {
 	Password string `json:"password"`
 }
 type ShortenReq struct {
-	URL         string `json:"original_url"`
-	Slug        string `json:"slug"`
-	UTMSource   string `json:"utm_source"`
-	UTMMedium   string `json:"utm_medium"`
-	UTMCampaign string `json:"utm_campaign"`
+	URL         string            `json:"original_url"`
+	Slug        string            `json:"slug"`
+	UTMSource   string            `json:"utm_source"`
+	UTMMedium   string            `json:"utm_medium"`
+	UTMCampaign string            `json:"utm_campaign"`
+	UTMTerm     string            `json:"utm_term"`
+	UTMContent  string            `json:"utm_content"`
+	ExtraParams map[string]string `json:"extra_params"`
+	UTMPolicy   string            `json:"utm_policy"`
+	PassQuery   bool              `json:"pass_query"`
+	Title       string            `json:"title"`
+	CampaignID  *uuid.UUID        `json:"campaign_id"`
+	PrivacyMode string            `json:"privacy_mode"`
 }
 type AuthTokenRes struct {
 	RefreshToken string `json:"refreshToken"`
@@ -34,35 +43,129 @@ type AuthRes struct {
 	Name         string    `json:"name"`
 	Email        string    `json:"email"`
 }
+type MfaChallengeRes struct {
+	Status         string `json:"status"`
+	ChallengeToken string `json:"challengeToken"`
+}
+type MfaLoginReq struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recoveryCode"`
+}
+type MfaCodeReq struct {
+	Code string `json:"code"`
+}
+type MfaEnrollRes struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+	QRCode     string `json:"qrCode"`
+}
+type RecoveryCodesRes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+type PasswordConfirmReq struct {
+	Password string `json:"password"`
+}
+type OIDCStartRes struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+}
+type OIDCCallbackReq struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
 type TokenReq struct {
 	RefreshToken string `json:"refreshToken"`
 }
 type UserInfoReq struct {
-	Id        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"createdAt"`
+	Id                   uuid.UUID  `json:"id"`
+	Name                 string     `json:"name"`
+	Email                string     `json:"email"`
+	EmailVerified        bool       `json:"emailVerified"`
+	MfaEnabled           bool       `json:"mfaEnabled"`
+	CreatedAt            time.Time  `json:"createdAt"`
+	DeletionScheduledFor *time.Time `json:"deletionScheduledFor"`
+	PrivacyMode          string     `json:"privacyMode"`
+}
+type PrivacyModeReq struct {
+	PrivacyMode string `json:"privacy_mode"`
+}
+type EmailTokenReq struct {
+	Token string `json:"token"`
+}
+type ForgotPasswordReq struct {
+	Email string `json:"email"`
+}
+type ResetPasswordReq struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+type SignInRes struct {
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	CreatedAt time.Time `json:"created_at"`
 }
 type Link struct {
-	Slug         string `json:"slug"`
-	OriginalURL  string `json:"original_url"`
-	IsActive     bool   `json:"is_enabled"`
-	CreatedAt    string `json:"created_at"`
-	TotalClicks  int    `json:"total_clicks"`
-	UniqueClicks int    `json:"unique_clicks"`
-	UTMSource    string `json:"utm_source"`
-	UTMMedium    string `json:"utm_medium"`
-	UTMCampaign  string `json:"utm_campaign"`
-	ShortURL     string `json:"short_url"`
-	UpdatedAt    string `json:"updated_at"`
+	Slug         string     `json:"slug"`
+	OriginalURL  string     `json:"original_url"`
+	Title        string     `json:"title"`
+	IsActive     bool       `json:"is_enabled"`
+	CreatedAt    string     `json:"created_at"`
+	TotalClicks  int        `json:"total_clicks"`
+	UniqueClicks int        `json:"unique_clicks"`
+	UTMSource    string     `json:"utm_source"`
+	UTMMedium    string     `json:"utm_medium"`
+	UTMCampaign  string     `json:"utm_campaign"`
+	ShortURL     string     `json:"short_url"`
+	UpdatedAt    string     `json:"updated_at"`
+	Tags         []string   `json:"tags"`
+	FolderID     *uuid.UUID `json:"folder_id"`
+	CampaignID   *uuid.UUID `json:"campaign_id"`
+	PrivacyMode  string     `json:"privacy_mode"`
+	// Result of the latest destination check: unknown, healthy, failing or broken
+	HealthStatus     string     `json:"health_status"`
+	HealthStatusCode *int32     `json:"health_status_code"`
+	HealthCheckedAt  *time.Time `json:"health_checked_at"`
+	// Quarantined links show a warning page instead of redirecting
+	IsQuarantined    bool   `json:"is_quarantined"`
+	QuarantineReason string `json:"quarantine_reason"`
+	// Blocked by an admin; only an admin can unblock it
+	IsBlocked     bool   `json:"is_blocked"`
+	BlockedReason string `json:"blocked_reason"`
 }
 type LinkReq struct {
-	URL         string `json:"original_url"`
-	Slug        string `json:"slug"`
-	UTMSource   string `json:"utm_source"`
-	UTMMedium   string `json:"utm_medium"`
-	UTMCampaign string `json:"utm_campaign"`
-	CreatedAt   string `json:"created_at"`
+	URL               string            `json:"original_url"`
+	Slug              string            `json:"slug"`
+	UTMSource         string            `json:"utm_source"`
+	UTMMedium         string            `json:"utm_medium"`
+	UTMCampaign       string            `json:"utm_campaign"`
+	UTMTerm           string            `json:"utm_term"`
+	UTMContent        string            `json:"utm_content"`
+	ExtraParams       map[string]string `json:"extra_params"`
+	UTMPolicy         string            `json:"utm_policy"`
+	PassQuery         bool              `json:"pass_query"`
+	Title             string            `json:"title"`
+	CreatedAt         string            `json:"created_at"`
+	PrivacyMode       string            `json:"privacy_mode"`
+	HealthAction      string            `json:"health_action"`
+	HealthFallbackURL string            `json:"health_fallback_url"`
+}
+type LinkHealthActionReq struct {
+	Action      string `json:"action" binding:"required"`
+	FallbackURL string `json:"fallback_url"`
+}
+type LinkHealthRes struct {
+	Status              string     `json:"status"`
+	StatusCode          *int32     `json:"status_code"`
+	LatencyMs           int32      `json:"latency_ms"`
+	FinalURL            string     `json:"final_url"`
+	Error               string     `json:"error"`
+	TLSError            string     `json:"tls_error"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	CheckedAt           *time.Time `json:"checked_at"`
+	LastHealthyAt       *time.Time `json:"last_healthy_at"`
+	Action              string     `json:"action"`
+	FallbackURL         string     `json:"fallback_url"`
 }
 type DeleteRes struct {
 	Success bool   `json:"success"`
@@ -73,6 +176,8 @@ type Analytics struct {
 	UniqueClicks  int             `json:"unique_clicks"`
 	ByCountry     map[string]int  `json:"by_country"`
 	ByReferrer    map[string]int  `json:"by_referrer"`
+	BySource      map[string]int  `json:"by_source"`
+	ByChannel     map[string]int  `json:"by_channel"`
 	UTMBreakdown  UTMB            `json:"utm_breakdown"`
 	ClicksByDate  map[string]int  `json:"clicks_by_date"`
 	DeviceSummary DeviceAnalytics `json:"device_summary"`
@@ -97,6 +202,13 @@ type ProfileUpdateReq struct {
 	ResourceType string `json:"type"`
 	Value        string `json:"value"`
 }
+type ChangePasswordReq struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+type ChangeEmailReq struct {
+	Email string `json:"email"`
+}
 type SuccessRes struct {
 	Success bool `json:"success"`
 }
@@ -105,6 +217,18 @@ type UTMReq struct {
 	UTMMedium   string `json:"utm_medium"`
 	UTMCampaign string `json:"utm_campaign"`
 }
+
+// Null fields keep their value, except the three classic UTM fields which are always replaced
+type LinkUTMReq struct {
+	UTMSource   string            `json:"utm_source"`
+	UTMMedium   string            `json:"utm_medium"`
+	UTMCampaign string            `json:"utm_campaign"`
+	UTMTerm     *string           `json:"utm_term"`
+	UTMContent  *string           `json:"utm_content"`
+	ExtraParams map[string]string `json:"extra_params"`
+	UTMPolicy   *string           `json:"utm_policy"`
+	PassQuery   *bool             `json:"pass_query"`
+}
 type SlugReq struct {
 	Slug string `json:"slug"`
 }
@@ -121,9 +245,38 @@ type RedirectReq struct {
 	IsUnique bool         `json:"isUnique"`
 	Referrer string       `json:"referrer"`
 	UTM      UTMReq       `json:"utm_parameters"`
+	Query    string       `json:"query"`
 }
 type RedirectResponse struct {
-	OriginalURL string `json:"original_url"`
+	OriginalURL string        `json:"original_url"`
+	Fallback    *FallbackInfo `json:"fallback,omitempty"`
+}
+
+// Why a link couldn't be followed and what the account wants visitors to see instead
+type FallbackInfo struct {
+	Reason   string `json:"reason"`
+	Action   string `json:"action"`
+	Template string `json:"template,omitempty"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+}
+type FallbackPageReq struct {
+	Action     string `json:"action" binding:"required"`
+	URL        string `json:"url"`
+	Template   string `json:"template"`
+	StatusCode int    `json:"status_code"`
+	Title      string `json:"title"`
+	Message    string `json:"message"`
+}
+type FallbackPageRes struct {
+	Kind       string `json:"kind"`
+	Action     string `json:"action"`
+	URL        string `json:"url,omitempty"`
+	Template   string `json:"template,omitempty"`
+	StatusCode int32  `json:"status_code"`
+	Title      string `json:"title"`
+	Message    string `json:"message"`
+	Configured bool   `json:"configured"`
 }
 type IPDetail struct {
 	Status  string `json:"status"`
@@ -138,3 +291,339 @@ type ClickWithDevice struct {
 	Timezone   string
 	UserAgent  string
 }
+type TagReq struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+type TagRes struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	LinkCount int64     `json:"link_count"`
+	CreatedAt string    `json:"created_at"`
+}
+type FolderReq struct {
+	Name     string     `json:"name"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+type FolderRes struct {
+	ID        uuid.UUID  `json:"id"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	Name      string     `json:"name"`
+	LinkCount int64      `json:"link_count"`
+	CreatedAt string     `json:"created_at"`
+}
+type LinkTagsReq struct {
+	Slugs  []string    `json:"slugs"`
+	Add    []uuid.UUID `json:"add"`
+	Remove []uuid.UUID `json:"remove"`
+}
+type LinkFolderReq struct {
+	Slugs    []string   `json:"slugs"`
+	FolderID *uuid.UUID `json:"folder_id"`
+}
+type BulkUpdateRes struct {
+	Success bool  `json:"success"`
+	Updated int64 `json:"updated"`
+}
+type CampaignReq struct {
+	Name        string `json:"name"`
+	UTMSource   string `json:"utm_source"`
+	UTMMedium   string `json:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign"`
+	StartsAt    string `json:"starts_at"`
+	EndsAt      string `json:"ends_at"`
+	BudgetNote  string `json:"budget_note"`
+}
+type CampaignRes struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	UTMSource   string    `json:"utm_source"`
+	UTMMedium   string    `json:"utm_medium"`
+	UTMCampaign string    `json:"utm_campaign"`
+	StartsAt    *string   `json:"starts_at"`
+	EndsAt      *string   `json:"ends_at"`
+	BudgetNote  string    `json:"budget_note"`
+	Status      string    `json:"status"`
+	LinkCount   int64     `json:"link_count"`
+	CreatedAt   string    `json:"created_at"`
+}
+type LinkCampaignReq struct {
+	Slugs      []string   `json:"slugs"`
+	CampaignID *uuid.UUID `json:"campaign_id"`
+}
+type AnalyticsPeriod struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+type AnalyticsTotals struct {
+	TotalClicks  int64 `json:"total_clicks"`
+	UniqueClicks int64 `json:"unique_clicks"`
+	ActiveLinks  int64 `json:"active_links"`
+}
+type AnalyticsChange struct {
+	TotalClicks  *float64 `json:"total_clicks"`
+	UniqueClicks *float64 `json:"unique_clicks"`
+	ActiveLinks  *float64 `json:"active_links"`
+}
+type TimeseriesPoint struct {
+	Date         string `json:"date"`
+	TotalClicks  int64  `json:"total_clicks"`
+	UniqueClicks int64  `json:"unique_clicks"`
+}
+type TopLink struct {
+	Slug         string `json:"slug"`
+	OriginalURL  string `json:"original_url"`
+	Title        string `json:"title"`
+	ShortURL     string `json:"short_url"`
+	TotalClicks  int64  `json:"total_clicks"`
+	UniqueClicks int64  `json:"unique_clicks"`
+}
+type DimensionCount struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+type AccountAnalytics struct {
+	Period         AnalyticsPeriod   `json:"period"`
+	PreviousPeriod AnalyticsPeriod   `json:"previous_period"`
+	Totals         AnalyticsTotals   `json:"totals"`
+	PreviousTotals AnalyticsTotals   `json:"previous_totals"`
+	Change         AnalyticsChange   `json:"change"`
+	Timeseries     []TimeseriesPoint `json:"timeseries"`
+	TopLinks       []TopLink         `json:"top_links"`
+	TopCountries   []DimensionCount  `json:"top_countries"`
+	TopReferrers   []DimensionCount  `json:"top_referrers"`
+	TopSources     []DimensionCount  `json:"top_sources"`
+	TopChannels    []DimensionCount  `json:"top_channels"`
+	TopDeviceTypes []DimensionCount  `json:"top_device_types"`
+	TopPlatforms   []DimensionCount  `json:"top_platforms"`
+}
+type AccountDeletionRes struct {
+	Success              bool      `json:"success"`
+	DeletionScheduledFor time.Time `json:"deletion_scheduled_for"`
+}
+type ExportIdentity struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// A change to a link, from the audit log, with only the fields it changed
+type ExportRevision struct {
+	LinkID    string          `json:"link_id"`
+	Action    string          `json:"action"`
+	ActorID   *uuid.UUID      `json:"actor_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+type ExportLink struct {
+	Slug        string            `json:"slug"`
+	OriginalURL string            `json:"original_url"`
+	Title       string            `json:"title"`
+	IsActive    bool              `json:"is_enabled"`
+	UTMSource   string            `json:"utm_source"`
+	UTMMedium   string            `json:"utm_medium"`
+	UTMCampaign string            `json:"utm_campaign"`
+	UTMTerm     string            `json:"utm_term"`
+	UTMContent  string            `json:"utm_content"`
+	ExtraParams map[string]string `json:"extra_params"`
+	UTMPolicy   string            `json:"utm_policy"`
+	PassQuery   bool              `json:"pass_query"`
+	Tags        []string          `json:"tags"`
+	FolderID    *uuid.UUID        `json:"folder_id"`
+	CampaignID  *uuid.UUID        `json:"campaign_id"`
+	PrivacyMode string            `json:"privacy_mode"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   *time.Time        `json:"updated_at"`
+	// Set while the link is in the trash
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+type LiveStreamTokenRes struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+type ClickEvent struct {
+	ShortLinkID uuid.UUID  `json:"-"`
+	Slug        string     `json:"slug"`
+	Country     string     `json:"country"`
+	DeviceType  string     `json:"device_type"`
+	Platform    string     `json:"platform"`
+	Referrer    string     `json:"referrer"`
+	Source      string     `json:"source"`
+	Channel     string     `json:"channel"`
+	CampaignID  *uuid.UUID `json:"campaign_id"`
+	IsUnique    bool       `json:"is_unique"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+type WebhookReq struct {
+	URL      string   `json:"url"`
+	Events   []string `json:"events"`
+	IsActive *bool    `json:"is_active"`
+}
+type WebhookRes struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	IsActive  bool      `json:"is_active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+type WebhookDeliveryRes struct {
+	ID             uuid.UUID       `json:"id"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus *int32          `json:"response_status"`
+	LastError      string          `json:"last_error"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Payload        json.RawMessage `json:"payload"`
+}
+type WebhookPayload struct {
+	ID        uuid.UUID `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+type WebhookLink struct {
+	Slug        string     `json:"slug"`
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	Title       string     `json:"title"`
+	IsActive    bool       `json:"is_enabled"`
+	Quarantined bool       `json:"is_quarantined"`
+	UTMSource   string     `json:"utm_source"`
+	UTMMedium   string     `json:"utm_medium"`
+	UTMCampaign string     `json:"utm_campaign"`
+	CampaignID  *uuid.UUID `json:"campaign_id"`
+	FolderID    *uuid.UUID `json:"folder_id"`
+}
+type AlertRuleReq struct {
+	Slug          string `json:"slug"`
+	Type          string `json:"type"`
+	Threshold     int64  `json:"threshold"`
+	WindowMinutes int32  `json:"window_minutes"`
+	Channel       string `json:"channel"`
+}
+type AlertRuleRes struct {
+	ID              uuid.UUID  `json:"id"`
+	Slug            string     `json:"slug"`
+	Type            string     `json:"type"`
+	Threshold       int64      `json:"threshold"`
+	WindowMinutes   int32      `json:"window_minutes"`
+	Channel         string     `json:"channel"`
+	IsMuted         bool       `json:"is_muted"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+type AlertMuteReq struct {
+	Muted bool `json:"muted"`
+}
+type ScheduleWindow struct {
+	Days  []int  `json:"days"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+type ScheduleReq struct {
+	Timezone     string           `json:"timezone"`
+	ActivateAt   string           `json:"activate_at"`
+	DeactivateAt string           `json:"deactivate_at"`
+	Windows      []ScheduleWindow `json:"windows"`
+	FallbackURL  string           `json:"fallback_url"`
+}
+type ScheduleRes struct {
+	Timezone     string           `json:"timezone"`
+	ActivateAt   *time.Time       `json:"activate_at"`
+	DeactivateAt *time.Time       `json:"deactivate_at"`
+	Windows      []ScheduleWindow `json:"windows"`
+	FallbackURL  string           `json:"fallback_url"`
+	IsOpen       bool             `json:"is_open"`
+}
+
+type ModerationReq struct {
+	Reason string `json:"reason"`
+}
+type AbuseReportReq struct {
+	Category string `json:"category"`
+	Details  string `json:"details"`
+	Email    string `json:"email"`
+}
+type ResolveReportReq struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+type AbuseReportRes struct {
+	ID             uuid.UUID  `json:"id"`
+	LinkID         *uuid.UUID `json:"link_id"`
+	Slug           string     `json:"slug"`
+	Category       string     `json:"category"`
+	Details        string     `json:"details"`
+	ReporterEmail  string     `json:"reporter_email"`
+	Status         string     `json:"status"`
+	ResolutionNote string     `json:"resolution_note"`
+	ResolvedBy     *uuid.UUID `json:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+type AdminUserRes struct {
+	ID               uuid.UUID  `json:"id"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	EmailVerified    bool       `json:"email_verified"`
+	SuspendedAt      *time.Time `json:"suspended_at"`
+	SuspensionReason string     `json:"suspension_reason"`
+	LinkCount        int64      `json:"link_count"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+type AdminLinkRes struct {
+	ID               uuid.UUID  `json:"id"`
+	Slug             string     `json:"slug"`
+	OriginalURL      string     `json:"original_url"`
+	OwnerID          uuid.UUID  `json:"owner_id"`
+	OwnerEmail       string     `json:"owner_email"`
+	IsActive         bool       `json:"is_enabled"`
+	BlockedAt        *time.Time `json:"blocked_at"`
+	BlockedReason    string     `json:"blocked_reason"`
+	QuarantinedAt    *time.Time `json:"quarantined_at"`
+	QuarantineReason string     `json:"quarantine_reason"`
+	QuarantinedBy    string     `json:"quarantined_by"`
+	DeletedAt        *time.Time `json:"deleted_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+type SystemStatsRes struct {
+	Users                    int64 `json:"users"`
+	SuspendedUsers           int64 `json:"suspended_users"`
+	NewUsers7d               int64 `json:"new_users_7d"`
+	Links                    int64 `json:"links"`
+	ActiveLinks              int64 `json:"active_links"`
+	QuarantinedLinks         int64 `json:"quarantined_links"`
+	BlockedLinks             int64 `json:"blocked_links"`
+	Clicks24h                int64 `json:"clicks_24h"`
+	OpenReports              int64 `json:"open_reports"`
+	PendingWebhookDeliveries int64 `json:"pending_webhook_deliveries"`
+}
+type AuditLogRes struct {
+	ID         uuid.UUID       `json:"id"`
+	ActorID    *uuid.UUID      `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IPAddress  string          `json:"ip_address"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type TrashedLinkRes struct {
+	Slug        string    `json:"slug"`
+	OriginalURL string    `json:"original_url"`
+	Title       string    `json:"title"`
+	CreatedAt   time.Time `json:"created_at"`
+	DeletedAt   time.Time `json:"deleted_at"`
+	// When the purge job removes the link for good
+	PurgeAt time.Time `json:"purge_at"`
+}
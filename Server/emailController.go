@@ -0,0 +1,225 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailTokenVerify = "verify_email"
+	emailTokenReset  = "password_reset"
+	emailTokenChange = "email_change"
+
+	verifyTokenExpiry = 24 * time.Hour
+	resetTokenExpiry  = time.Hour
+	changeTokenExpiry = 24 * time.Hour
+)
+
+// Replaces any pending token of the same purpose and returns the raw token for the mail link
+func issueEmailToken(c *gin.Context, q *database.Queries, userID uuid.UUID, purpose string, newEmail string, expiry time.Duration) (string, error) {
+	if err := q.DeleteUnusedEmailTokens(c, database.DeleteUnusedEmailTokensParams{
+		UserID:  userID,
+		Purpose: purpose,
+	}); err != nil {
+		return "", err
+	}
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+	err = q.CreateEmailToken(c, database.CreateEmailTokenParams{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		Purpose:   purpose,
+		NewEmail:  newEmail,
+		ExpiresAt: time.Now().Add(expiry),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Returns sql.ErrNoRows when the token is unknown, expired or already used
+func (cfg *apiCfg) consumeEmailToken(c *gin.Context, token string, purpose string) (database.EmailToken, error) {
+	tok, err := cfg.db.RetrieveValidEmailToken(c, database.RetrieveValidEmailTokenParams{
+		TokenHash: hashToken(token),
+		Purpose:   purpose,
+	})
+	if err != nil {
+		return database.EmailToken{}, err
+	}
+	affected, err := cfg.db.MarkEmailTokenUsed(c, tok.ID)
+	if err != nil {
+		return database.EmailToken{}, err
+	}
+	if affected == 0 {
+		return database.EmailToken{}, sql.ErrNoRows
+	}
+	return tok, nil
+}
+
+func (cfg *apiCfg) frontendLink(path string, token string) string {
+	return fmt.Sprintf("%s/%s?token=%s", strings.TrimSuffix(cfg.frontendOrigin, "/"), path, token)
+}
+
+func (cfg *apiCfg) sendVerificationEmail(c *gin.Context, user database.User) error {
+	token, err := issueEmailToken(c, cfg.db, user.ID, emailTokenVerify, "", verifyTokenExpiry)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below:\n%s\n\nThe link expires in 24 hours.",
+		user.Name, cfg.frontendLink("verify-email", token))
+	return cfg.mailer.Send(user.Email, "Verify your email address", body)
+}
+
+func (cfg *apiCfg) sendEmailChangeConfirmation(user database.User, newEmail string, token string) error {
+	body := fmt.Sprintf("Hi %s,\n\nConfirm that you want to use this address for your account by opening the link below:\n%s\n\nThe link expires in 24 hours. If you did not request this change, ignore this email.",
+		user.Name, cfg.frontendLink("confirm-email", token))
+	return cfg.mailer.Send(newEmail, "Confirm your new email address", body)
+}
+
+func (cfg *apiCfg) verifyEmail(c *gin.Context) {
+	var data EmailTokenReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	tok, err := cfg.consumeEmailToken(c, data.Token, emailTokenVerify)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err := cfg.db.VerifyUserEmail(c, tok.UserID); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) ResendVerification(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	if user.EmailVerified {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already verified"})
+		return
+	}
+	if err := cfg.sendVerificationEmail(c, user); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) confirmEmailChange(c *gin.Context) {
+	var data EmailTokenReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	tok, err := cfg.consumeEmailToken(c, data.Token, emailTokenChange)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	err = cfg.db.UpdateUserEmail(c, database.UpdateUserEmailParams{ID: tok.UserID, Email: tok.NewEmail})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			if pqErr.Code == "23505" { // Unique violation
+				c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+				return
+			}
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) forgotPassword(c *gin.Context) {
+	var data ForgotPasswordReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	// Always answer the same way so the endpoint can't be used to probe for accounts
+	user, err := cfg.db.RetrieveUserByEmail(c, data.Email)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Failed to look up user for password reset: %v", err)
+		}
+		c.JSON(http.StatusOK, SuccessRes{Success: true})
+		return
+	}
+	// A reset sent to an unconfirmed address could hand the account to a stranger
+	if !user.EmailVerified {
+		c.JSON(http.StatusOK, SuccessRes{Success: true})
+		return
+	}
+	token, err := issueEmailToken(c, cfg.db, user.ID, emailTokenReset, "", resetTokenExpiry)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	body := fmt.Sprintf("Hi %s,\n\nReset your password by opening the link below:\n%s\n\nThe link expires in 1 hour and can only be used once. If you did not request a reset, ignore this email.",
+		user.Name, cfg.frontendLink("reset-password", token))
+	if err := cfg.mailer.Send(user.Email, "Reset your password", body); err != nil {
+		log.Printf("Failed to send password reset email: %v", err)
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) resetPassword(c *gin.Context) {
+	var data ResetPasswordReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if err := cfg.validatePassword(data.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tok, err := cfg.consumeEmailToken(c, data.Token, emailTokenReset)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	if err := cfg.db.UpdateUserPassword(c, database.UpdateUserPasswordParams{ID: tok.UserID, Password: string(hashedPassword)}); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	// Sign out every session that may have been opened with the old password
+	if err := cfg.db.DeleteToken(c, tok.UserID); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
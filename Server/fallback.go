@@ -0,0 +1,452 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	fallbackNotFound = "not_found"
+	fallbackDisabled = "disabled"
+	fallbackExpired  = "expired"
+	fallbackBlocked  = "blocked"
+	// Always the warning page, whatever the owner configured
+	fallbackQuarantined = "quarantined"
+
+	fallbackActionRedirect = "redirect"
+	fallbackActionTemplate = "template"
+	fallbackActionStatus   = "status"
+
+	defaultFallbackTemplate = "default"
+	maxFallbackTitle        = 100
+	maxFallbackMessage      = 500
+	// Visitors are remembered for a year when working out unique clicks on the native route
+	visitedCookieAge = 365 * 24 * 60 * 60
+)
+
+//go:embed templates/*.html
+var fallbackTemplateFS embed.FS
+
+var fallbackTemplates = template.Must(template.ParseFS(fallbackTemplateFS, "templates/*.html"))
+
+// Templates an account can pick for its hosted fallback pages
+var hostedTemplates = map[string]bool{
+	"default": true,
+	"minimal": true,
+}
+
+type fallbackDefault struct {
+	status  int
+	title   string
+	message string
+}
+
+// What a visitor gets when the account hasn't configured anything
+var fallbackDefaults = map[string]fallbackDefault{
+	fallbackNotFound: {http.StatusNotFound, "Link not found", "This link doesn't exist or has been removed."},
+	fallbackDisabled: {http.StatusGone, "Link disabled", "The owner of this link has switched it off."},
+	fallbackExpired:  {http.StatusGone, "Link unavailable", "This link isn't available right now."},
+	fallbackBlocked:  {http.StatusForbidden, "Link blocked", "This link has been blocked."},
+}
+
+// Unknown slugs have no owner, so not_found always uses the built-in page
+var accountFallbackKinds = []string{fallbackDisabled, fallbackExpired, fallbackBlocked}
+
+type fallbackPageData struct {
+	Status  int
+	Title   string
+	Message string
+	Slug    string
+	HomeURL string
+}
+
+func builtInFallback(kind string) database.FallbackPage {
+	return withFallbackText(database.FallbackPage{
+		Kind:       kind,
+		Action:     fallbackActionTemplate,
+		Template:   defaultFallbackTemplate,
+		StatusCode: int32(fallbackDefaults[kind].status),
+	})
+}
+
+// Fills in the built-in title and message where the account left them out
+func withFallbackText(page database.FallbackPage) database.FallbackPage {
+	defaults := fallbackDefaults[page.Kind]
+	page.Title = defaultString(page.Title, defaults.title)
+	page.Message = defaultString(page.Message, defaults.message)
+	return page
+}
+
+// Also reports whether the owner configured the page
+func (cfg *apiCfg) fallbackFor(ctx context.Context, link database.ShortLink, kind string) (database.FallbackPage, bool, error) {
+	switch kind {
+	case fallbackQuarantined:
+		return quarantinePage(link), false, nil
+	case fallbackNotFound:
+		return builtInFallback(kind), false, nil
+	}
+	page, err := cfg.db.GetFallbackPage(ctx, database.GetFallbackPageParams{
+		UserID: link.UserID,
+		Kind:   kind,
+	})
+	// A blocked link must not lead anywhere, whatever the account saved before
+	if errors.Is(err, sql.ErrNoRows) || (kind == fallbackBlocked && page.Action == fallbackActionRedirect) {
+		return builtInFallback(kind), false, nil
+	}
+	if err != nil {
+		return database.FallbackPage{}, false, err
+	}
+	return withFallbackText(page), true, nil
+}
+
+// A non-empty kind says why the slug can't be followed; scheduleFallback wins over account pages
+func (cfg *apiCfg) linkState(c *gin.Context, slug string) (link database.ShortLink, kind string, scheduleFallback string, err error) {
+	link, err = cfg.db.RetrieveShortLinkBySlug(c, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return link, fallbackNotFound, "", nil
+	}
+	if err != nil {
+		return link, "", "", err
+	}
+	if link.QuarantinedAt.Valid {
+		return link, fallbackQuarantined, "", nil
+	}
+	// Links an admin blocked, and every link of a suspended account, lead nowhere
+	if link.BlockedAt.Valid {
+		return link, fallbackBlocked, "", nil
+	}
+	suspended, err := cfg.db.IsUserSuspended(c, link.UserID)
+	if err != nil {
+		return link, "", "", err
+	}
+	if suspended {
+		return link, fallbackBlocked, "", nil
+	}
+	schedule, err := cfg.linkScheduleFor(c, link)
+	if err != nil {
+		return link, "", "", err
+	}
+	if schedule != nil {
+		scheduleFallback = schedule.FallbackUrl
+	}
+	if !link.IsActive.Bool {
+		return link, fallbackDisabled, scheduleFallback, nil
+	}
+	if schedule != nil && !scheduleOpen(*schedule, cfg.clock.Now()) {
+		return link, fallbackExpired, scheduleFallback, nil
+	}
+	unhealthy, err := cfg.healthFallbackActive(c, link)
+	if err != nil {
+		return link, "", "", err
+	}
+	if unhealthy {
+		link.OriginalUrl = link.HealthFallbackUrl
+	}
+	return link, "", "", nil
+}
+
+// Fallbacks are never cached, so re-enabling a link takes effect right away
+func (cfg *apiCfg) renderFallback(c *gin.Context, page database.FallbackPage, slug string) {
+	c.Header("Cache-Control", "no-store")
+	switch page.Action {
+	case fallbackActionRedirect:
+		c.Redirect(http.StatusFound, page.Url)
+		return
+	case fallbackActionStatus:
+		c.String(int(page.StatusCode), http.StatusText(int(page.StatusCode)))
+		return
+	}
+	name := page.Template
+	if fallbackTemplates.Lookup(name+".html") == nil {
+		name = defaultFallbackTemplate
+	}
+	var buf bytes.Buffer
+	if err := fallbackTemplates.ExecuteTemplate(&buf, name+".html", fallbackPageData{
+		Status:  int(page.StatusCode),
+		Title:   page.Title,
+		Message: page.Message,
+		Slug:    slug,
+		HomeURL: cfg.frontendOrigin,
+	}); err != nil {
+		log.Printf("Failed to render fallback page: %v", err)
+		c.String(int(page.StatusCode), http.StatusText(int(page.StatusCode)))
+		return
+	}
+	c.Data(int(page.StatusCode), "text/html; charset=utf-8", buf.Bytes())
+}
+
+// Without a configured page the JSON responses stay as before
+func (cfg *apiCfg) fallbackJSON(c *gin.Context, link database.ShortLink, kind string, scheduleFallback string) {
+	// While a scheduled link is off, visitors go to its fallback URL if it has one
+	if scheduleFallback != "" {
+		c.JSON(http.StatusOK, RedirectResponse{OriginalURL: scheduleFallback})
+		return
+	}
+	page, configured, err := cfg.fallbackFor(c, link, kind)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !configured {
+		switch kind {
+		case fallbackNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+			return
+		case fallbackDisabled, fallbackExpired:
+			page.StatusCode = http.StatusMethodNotAllowed
+		}
+	}
+	if page.Action == fallbackActionRedirect {
+		c.JSON(http.StatusOK, RedirectResponse{OriginalURL: page.Url})
+		return
+	}
+	c.JSON(int(page.StatusCode), RedirectResponse{
+		OriginalURL: "",
+		Fallback: &FallbackInfo{
+			Reason:   kind,
+			Action:   page.Action,
+			Template: page.Template,
+			Title:    page.Title,
+			Message:  page.Message,
+		},
+	})
+}
+
+// Best effort device details for visitors that bypass the frontend
+func deviceFromRequest(c *gin.Context) DeviceStruct {
+	ua := c.Request.UserAgent()
+	lower := strings.ToLower(ua)
+	device := DeviceStruct{UserAgent: ua, DeviceType: "desktop"}
+	switch {
+	case strings.Contains(lower, "ipad") || (strings.Contains(lower, "android") && !strings.Contains(lower, "mobile")):
+		device.DeviceType = "tablet"
+	case strings.Contains(lower, "mobile") || strings.Contains(lower, "iphone"):
+		device.DeviceType = "mobile"
+	}
+	switch {
+	case strings.Contains(lower, "iphone"):
+		device.Platform = "iPhone"
+	case strings.Contains(lower, "ipad"):
+		device.Platform = "iPad"
+	case strings.Contains(lower, "android"):
+		device.Platform = "Linux armv8l"
+	case strings.Contains(lower, "windows"):
+		device.Platform = "Win32"
+	case strings.Contains(lower, "macintosh"):
+		device.Platform = "MacIntel"
+	case strings.Contains(lower, "linux"):
+		device.Platform = "Linux x86_64"
+	}
+	language, _, _ := strings.Cut(c.GetHeader("Accept-Language"), ",")
+	device.Language, _, _ = strings.Cut(strings.TrimSpace(language), ";")
+	return device
+}
+
+// Redirects directly, for links shared without the frontend
+func (cfg *apiCfg) FollowLink(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	link, kind, scheduleFallback, err := cfg.linkState(c, slug)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if kind != "" {
+		if scheduleFallback != "" {
+			c.Header("Cache-Control", "no-store")
+			c.Redirect(http.StatusFound, scheduleFallback)
+			return
+		}
+		page, _, err := cfg.fallbackFor(c, link, kind)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		cfg.renderFallback(c, page, slug)
+		return
+	}
+	query := c.Request.URL.Query()
+	data := RedirectReq{
+		Device:   deviceFromRequest(c),
+		Referrer: c.Request.Referer(),
+		UTM: UTMReq{
+			UTMSource:   query.Get("utm_source"),
+			UTMMedium:   query.Get("utm_medium"),
+			UTMCampaign: query.Get("utm_campaign"),
+		},
+		Query: c.Request.URL.RawQuery,
+	}
+	destination, err := buildDestinationURL(link, data.Query)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	cookie := "v_" + link.ID.String()
+	if _, err := c.Cookie(cookie); err != nil {
+		data.IsUnique = true
+		c.SetCookie(cookie, "1", visitedCookieAge, "/", "", c.Request.TLS != nil, true)
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, destination)
+	go cfg.SaveAnalytics(c.Copy(), link, data)
+}
+
+func fallbackPageRes(page database.FallbackPage, configured bool) FallbackPageRes {
+	return FallbackPageRes{
+		Kind:       page.Kind,
+		Action:     page.Action,
+		URL:        page.Url,
+		Template:   page.Template,
+		StatusCode: page.StatusCode,
+		Title:      page.Title,
+		Message:    page.Message,
+		Configured: configured,
+	}
+}
+
+func fallbackKindFromParam(c *gin.Context) (string, bool) {
+	kind := c.Param("kind")
+	if !slices.Contains(accountFallbackKinds, kind) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fallback not found"})
+		return "", false
+	}
+	return kind, true
+}
+
+// Includes the built-in pages the account hasn't replaced
+func (cfg *apiCfg) GetFallbackPages(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	pages, err := cfg.db.ListFallbackPagesByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	configured := map[string]database.FallbackPage{}
+	for _, page := range pages {
+		configured[page.Kind] = page
+	}
+	data := []FallbackPageRes{}
+	for _, kind := range accountFallbackKinds {
+		page, ok := configured[kind]
+		if !ok {
+			page = builtInFallback(kind)
+		}
+		data = append(data, fallbackPageRes(withFallbackText(page), ok))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+func (cfg *apiCfg) PutFallbackPage(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	kind, ok := fallbackKindFromParam(c)
+	if !ok {
+		return
+	}
+	var data FallbackPageReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	params := database.UpsertFallbackPageParams{
+		UserID:     user.ID,
+		Kind:       kind,
+		Action:     data.Action,
+		StatusCode: int32(fallbackDefaults[kind].status),
+	}
+	switch data.Action {
+	case fallbackActionRedirect:
+		if kind == fallbackBlocked {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Blocked links can't redirect"})
+			return
+		}
+		params.Url = strings.TrimSpace(data.URL)
+		if err := validateAbsoluteURL("url", params.Url); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Refused rather than quarantining every link of the account
+		match, err := cfg.threats.Check(c, params.Url)
+		if err != nil {
+			log.Printf("Failed to scan fallback URL: %v", err)
+		} else if match != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "url is flagged as " + threatLabels[match.ThreatType]})
+			return
+		}
+		params.StatusCode = http.StatusFound
+	case fallbackActionTemplate:
+		params.Template = defaultString(data.Template, defaultFallbackTemplate)
+		if !hostedTemplates[params.Template] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown template"})
+			return
+		}
+		params.Title = strings.TrimSpace(data.Title)
+		params.Message = strings.TrimSpace(data.Message)
+		// Left empty, the built-in text for the state is shown
+		if params.Title != "" {
+			if err := validateLabel("title", params.Title, maxFallbackTitle); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if params.Message != "" {
+			if err := validateLabel("message", params.Message, maxFallbackMessage); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		fallthrough
+	case fallbackActionStatus:
+		if data.StatusCode != 0 {
+			if data.StatusCode < 400 || data.StatusCode > 599 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "status_code must be between 400 and 599"})
+				return
+			}
+			params.StatusCode = int32(data.StatusCode)
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be redirect, template or status"})
+		return
+	}
+	page, err := cfg.db.UpsertFallbackPage(c, params)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, fallbackPageRes(withFallbackText(page), true))
+}
+
+// Goes back to the built-in behaviour for the state
+func (cfg *apiCfg) DeleteFallbackPage(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	kind, ok := fallbackKindFromParam(c)
+	if !ok {
+		return
+	}
+	rows, err := cfg.db.DeleteFallbackPage(c, database.DeleteFallbackPageParams{
+		UserID: user.ID,
+		Kind:   kind,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fallback not found"})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
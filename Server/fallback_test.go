@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestNotFoundFallbackIsNotConfigurable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake, conn := newFakeDB(t)
+	cfg := &apiCfg{db: database.New(conn), conn: conn}
+	user := database.User{ID: uuid.New()}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/fallbacks/not_found", strings.NewReader(`{"action":"template","template":"default"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "kind", Value: fallbackNotFound}}
+	c.Set("currentUser", user)
+	cfg.PutFallbackPage(c)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("PUT not_found: status %d, want 404", w.Code)
+	}
+	if saved := fake.called("UpsertFallbackPage"); len(saved) != 0 {
+		t.Fatalf("PUT not_found saved %d pages", len(saved))
+	}
+
+	// A row saved before not_found was refused must not show up as configured
+	fake.returns("ListFallbackPagesByUserId",
+		database.FallbackPage{UserID: user.ID, Kind: fallbackNotFound, Action: fallbackActionStatus, StatusCode: http.StatusNotFound, CreatedAt: time.Now()},
+		database.FallbackPage{UserID: user.ID, Kind: fallbackDisabled, Action: fallbackActionStatus, StatusCode: http.StatusGone, CreatedAt: time.Now()},
+	)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/fallbacks", nil)
+	c.Set("currentUser", user)
+	cfg.GetFallbackPages(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET: status %d: %s", w.Code, w.Body)
+	}
+	var res struct {
+		Data []FallbackPageRes `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, page := range res.Data {
+		kinds = append(kinds, page.Kind)
+		if page.Kind == fallbackDisabled && !page.Configured {
+			t.Fatal("the saved disabled page is reported as built-in")
+		}
+	}
+	if strings.Join(kinds, ",") != "disabled,expired,blocked" {
+		t.Fatalf("listed kinds %v, want disabled, expired and blocked", kinds)
+	}
+}
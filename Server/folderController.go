@@ -0,0 +1,278 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var errFolderNotFound = errors.New("folder not found")
+
+// Loads the user's folder from :id, answering 404 itself
+func (cfg *apiCfg) folderFromParam(c *gin.Context, userID uuid.UUID) (database.Folder, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+		return database.Folder{}, false
+	}
+	folder, err := cfg.db.RetrieveFolderByUserIdANDId(c, database.RetrieveFolderByUserIdANDIdParams{
+		UserID: userID,
+		ID:     id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+			return database.Folder{}, false
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return database.Folder{}, false
+	}
+	return folder, true
+}
+
+// Makes sure an optional folder id exists and belongs to the user
+func (cfg *apiCfg) checkFolderOwnership(c *gin.Context, userID uuid.UUID, folderID *uuid.UUID) error {
+	if folderID == nil {
+		return nil
+	}
+	_, err := cfg.db.RetrieveFolderByUserIdANDId(c, database.RetrieveFolderByUserIdANDIdParams{
+		UserID: userID,
+		ID:     *folderID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errFolderNotFound
+	}
+	return err
+}
+
+func folderConflict(c *gin.Context, err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if pqErr.Code == "23505" { // Unique violation
+			c.JSON(http.StatusConflict, gin.H{"error": "A folder with this name already exists here"})
+			return true
+		}
+	}
+	return false
+}
+
+func folderListRes(folders []database.ListFoldersByUserIdRow) []FolderRes {
+	data := []FolderRes{}
+	for _, folder := range folders {
+		data = append(data, FolderRes{
+			ID:        folder.ID,
+			ParentID:  uuidPtr(folder.ParentID),
+			Name:      folder.Name,
+			LinkCount: folder.LinkCount,
+			CreatedAt: folder.CreatedAt.String(),
+		})
+	}
+	return data
+}
+
+// Returns the flat list of folders; clients build the tree from parent_id
+func (cfg *apiCfg) GetFolders(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	folders, err := cfg.db.ListFoldersByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": folderListRes(folders)})
+}
+
+func (cfg *apiCfg) CreateFolder(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data FolderReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	name := strings.TrimSpace(data.Name)
+	if err := validateLabel("Folder name", name, maxFolderNameLength); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := cfg.checkFolderOwnership(c, user.ID, data.ParentID); err != nil {
+		if errors.Is(err, errFolderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "parent folder not found"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	folder, err := cfg.db.CreateFolder(c, database.CreateFolderParams{
+		UserID:   user.ID,
+		ParentID: nullUUIDFromPtr(data.ParentID),
+		Name:     name,
+	})
+	if err != nil {
+		if folderConflict(c, err) {
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, FolderRes{
+		ID:        folder.ID,
+		ParentID:  uuidPtr(folder.ParentID),
+		Name:      folder.Name,
+		CreatedAt: folder.CreatedAt.String(),
+	})
+}
+
+// An empty name keeps the current one; a null parent_id moves the folder to the top level
+func (cfg *apiCfg) UpdateFolder(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	folder, ok := cfg.folderFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	var data FolderReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	name := strings.TrimSpace(data.Name)
+	if name == "" {
+		name = folder.Name
+	}
+	if err := validateLabel("Folder name", name, maxFolderNameLength); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := cfg.checkFolderOwnership(c, user.ID, data.ParentID); err != nil {
+		if errors.Is(err, errFolderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "parent folder not found"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if data.ParentID != nil {
+		// A folder can't be moved into itself or any of its own subfolders
+		cycle, err := cfg.db.FolderSubtreeContains(c, database.FolderSubtreeContainsParams{
+			RootID:   folder.ID,
+			FolderID: *data.ParentID,
+		})
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		if cycle {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A folder can't be moved into one of its own subfolders"})
+			return
+		}
+	}
+	err := cfg.db.UpdateFolder(c, database.UpdateFolderParams{
+		UserID:   user.ID,
+		ID:       folder.ID,
+		Name:     name,
+		ParentID: nullUUIDFromPtr(data.ParentID),
+	})
+	if err != nil {
+		if folderConflict(c, err) {
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// Deletes the folder and its subfolders; their links are kept outside any folder
+func (cfg *apiCfg) DeleteFolder(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	folder, ok := cfg.folderFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	_, err := cfg.db.DeleteFolderByUserIdANDId(c, database.DeleteFolderByUserIdANDIdParams{
+		UserID: user.ID,
+		ID:     folder.ID,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// Aggregates clicks over every link in the folder and its subfolders
+func (cfg *apiCfg) GetFolderAnalytics(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	folder, ok := cfg.folderFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	rolledUpTo, err := cfg.clickRollupWatermark(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	rollups, err := cfg.db.DailyRollupsByFolder(c, database.DailyRollupsByFolderParams{
+		ID:     folder.ID,
+		Bucket: rolledUpTo,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	rows, err := cfg.db.AnalyticsRetrievalByFolder(c, database.AnalyticsRetrievalByFolderParams{
+		ID:        folder.ID,
+		CreatedAt: rolledUpTo,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	analyticsData := make([]database.AnalyticsRetrievalRow, 0, len(rows))
+	for _, row := range rows {
+		analyticsData = append(analyticsData, database.AnalyticsRetrievalRow(row))
+	}
+	data := newAnalytics()
+	addRollupData(&data, rollups)
+	sortAnalyticsData(&data, analyticsData)
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// Moves a batch of links into a folder, or out of any folder when folder_id is null
+func (cfg *apiCfg) MoveLinksToFolder(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data LinkFolderReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if len(data.Slugs) == 0 || len(data.Slugs) > maxBulkLinks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slugs must contain between 1 and 1000 links"})
+		return
+	}
+	if err := cfg.checkFolderOwnership(c, user.ID, data.FolderID); err != nil {
+		if errors.Is(err, errFolderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	links, err := cfg.db.MoveShortLinksToFolder(c, database.MoveShortLinksToFolderParams{
+		FolderID: nullUUIDFromPtr(data.FolderID),
+		UserID:   user.ID,
+		Slugs:    data.Slugs,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	for _, link := range links {
+		cfg.emitLinkEvent(c, webhookLinkUpdated, link)
+	}
+	c.JSON(http.StatusOK, BulkUpdateRes{Success: true, Updated: int64(len(links))})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sort"
+)
+
+type geoRange struct {
+	start   net.IP
+	end     net.IP
+	country string
+}
+
+// In-memory "START_IP,END_IP,COUNTRY" table, like the DB-IP country lite CSV
+type GeoDB struct {
+	ranges []geoRange
+}
+
+func LoadGeoDB(path string) (*GeoDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	db := &GeoDB{}
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("%s:%d: expected start, end and country", path, line)
+		}
+		start, end := net.ParseIP(record[0]).To16(), net.ParseIP(record[1]).To16()
+		if start == nil || end == nil {
+			return nil, fmt.Errorf("%s:%d: invalid IP range", path, line)
+		}
+		db.ranges = append(db.ranges, geoRange{start: start, end: end, country: record[2]})
+	}
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return bytes.Compare(db.ranges[i].start, db.ranges[j].start) < 0
+	})
+	return db, nil
+}
+
+// Returns the country of the range containing ip, or "" if there is none
+func (g *GeoDB) Country(ip string) string {
+	addr := net.ParseIP(ip).To16()
+	if addr == nil {
+		return ""
+	}
+	i := sort.Search(len(g.ranges), func(i int) bool {
+		return bytes.Compare(g.ranges[i].start, addr) > 0
+	})
+	if i == 0 || bytes.Compare(g.ranges[i-1].end, addr) < 0 {
+		return ""
+	}
+	return g.ranges[i-1].country
+}
+
+func newGeoDBFromEnv() *GeoDB {
+	path := os.Getenv("GEOIP_CSV")
+	if path == "" {
+		return nil
+	}
+	db, err := LoadGeoDB(path)
+	if err != nil {
+		log.Fatalf("Failed to load GEOIP_CSV: %v", err)
+	}
+	return db
+}
+
+// Uses the local table if configured, else ip-api.com except for privacy mode clicks
+func (cfg *apiCfg) lookupCountry(ip string, private bool) string {
+	if ip == "::1" || ip == "127.0.0.1" || ip == "localhost" {
+		return "Unknown"
+	}
+	if cfg.geoDB != nil {
+		if country := cfg.geoDB.Country(ip); country != "" {
+			return country
+		}
+		return "Unknown"
+	}
+	if private {
+		return "Unknown"
+	}
+	location, err := IPLocation(ip)
+	if err != nil || location == "" {
+		log.Printf("Failed to get IP location: %v", err)
+		return "Unknown"
+	}
+	return location
+}
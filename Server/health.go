@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	healthStatusUnknown = "unknown"
+	healthStatusHealthy = "healthy"
+	// The last check failed, but not often enough in a row to call the link broken
+	healthStatusFailing = "failing"
+	healthStatusBroken  = "broken"
+
+	healthActionNone     = "none"
+	healthActionDisable  = "disable"
+	healthActionFallback = "fallback"
+
+	linkHealthInterval  = time.Minute
+	linkHealthTimeout   = 10 * time.Second
+	linkHealthBatchSize = 20
+	linkHealthLease     = 120
+	// Failing links are rechecked soon so a break is confirmed within the hour
+	healthyRecheck         = 6 * time.Hour
+	failingRecheck         = 15 * time.Minute
+	healthFailureThreshold = 3
+	maxHealthRedirects     = 5
+	maxHealthErrorLength   = 500
+)
+
+var healthStatuses = map[string]bool{
+	healthStatusUnknown: true,
+	healthStatusHealthy: true,
+	healthStatusFailing: true,
+	healthStatusBroken:  true,
+}
+
+var healthActions = map[string]bool{
+	healthActionNone:     true,
+	healthActionDisable:  true,
+	healthActionFallback: true,
+}
+
+// Results are shown to the owner, so only public addresses are reached
+func newHealthClient() *http.Client {
+	return &http.Client{
+		Transport:     newPublicTransport(),
+		Timeout:       linkHealthTimeout,
+		CheckRedirect: checkHealthRedirect,
+	}
+}
+
+func checkHealthRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxHealthRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxHealthRedirects)
+	}
+	return nil
+}
+
+type healthResult struct {
+	statusCode int
+	latency    time.Duration
+	finalURL   string
+	err        string
+	tlsErr     string
+}
+
+// A destination that answers at all, even with a rate limit, is up
+func (r healthResult) failed() bool {
+	return r.err != "" || (r.statusCode >= 400 && r.statusCode != http.StatusTooManyRequests)
+}
+
+// Returns the certificate or handshake problem behind err, if that's what it is
+func tlsErrorMessage(err error) string {
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &verifyErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) || errors.As(err, &recordErr) {
+		return err.Error()
+	}
+	return ""
+}
+
+func truncateHealthError(message string) string {
+	if len(message) > maxHealthErrorLength {
+		return message[:maxHealthErrorLength]
+	}
+	return message
+}
+
+// HEAD, falling back to GET, following redirects to the final page
+func checkDestination(ctx context.Context, client *http.Client, destination string) healthResult {
+	start := time.Now()
+	resp, err := healthRequest(ctx, client, http.MethodHead, destination)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = healthRequest(ctx, client, http.MethodGet, destination)
+	}
+	result := healthResult{latency: time.Since(start)}
+	if errors.Is(err, errNonPublicAddress) {
+		// Says nothing about what answered, or didn't, at the address
+		result.err = errNonPublicAddress.Error()
+		return result
+	}
+	if err != nil {
+		result.err = truncateHealthError(err.Error())
+		result.tlsErr = truncateHealthError(tlsErrorMessage(err))
+		return result
+	}
+	defer resp.Body.Close()
+	result.statusCode = resp.StatusCode
+	result.finalURL = resp.Request.URL.String()
+	return result
+}
+
+func healthRequest(ctx context.Context, client *http.Client, method string, destination string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, destination, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "QuickLink-HealthCheck/1.0")
+	return client.Do(req)
+}
+
+// Checks due links in parallel batches, newest links first
+func (cfg *apiCfg) checkLinkHealth(ctx context.Context) error {
+	if err := cfg.db.SeedLinkHealth(ctx); err != nil {
+		return err
+	}
+	for {
+		due, err := cfg.db.ClaimDueLinkHealthChecks(ctx, database.ClaimDueLinkHealthChecksParams{
+			LeaseSeconds: linkHealthLease,
+			BatchSize:    linkHealthBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+		var wg sync.WaitGroup
+		for _, health := range due {
+			wg.Add(1)
+			go func(health database.LinkHealth) {
+				defer wg.Done()
+				if err := cfg.recordLinkHealth(ctx, health); err != nil {
+					log.Printf("Failed to record link health: %v", err)
+				}
+			}(health)
+		}
+		wg.Wait()
+		if len(due) < linkHealthBatchSize {
+			return nil
+		}
+	}
+}
+
+func (cfg *apiCfg) recordLinkHealth(ctx context.Context, previous database.LinkHealth) error {
+	link, err := cfg.db.RetrieveShortLinkById(ctx, previous.ShortLinkID)
+	if err != nil {
+		return err
+	}
+	result := checkDestination(ctx, cfg.healthClient, link.OriginalUrl)
+	params := nextLinkHealth(link.ID, previous, result)
+	if err := cfg.db.RecordLinkHealthCheck(ctx, params); err != nil {
+		return err
+	}
+	// Only the check that makes the link broken acts, so the owner hears once
+	if params.ConsecutiveFailures == healthFailureThreshold {
+		cfg.handleBrokenLink(ctx, link, params.Error)
+	}
+	return nil
+}
+
+// The link turns broken after enough failed checks in a row
+func nextLinkHealth(linkID uuid.UUID, previous database.LinkHealth, result healthResult) database.RecordLinkHealthCheckParams {
+	params := database.RecordLinkHealthCheckParams{
+		ShortLinkID:     linkID,
+		Status:          healthStatusHealthy,
+		StatusCode:      sql.NullInt32{Int32: int32(result.statusCode), Valid: result.statusCode != 0},
+		LatencyMs:       int32(result.latency.Milliseconds()),
+		FinalUrl:        result.finalURL,
+		Error:           result.err,
+		TlsError:        result.tlsErr,
+		IntervalSeconds: int32(healthyRecheck.Seconds()),
+	}
+	if result.failed() {
+		params.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+		params.Status = healthStatusFailing
+		params.IntervalSeconds = int32(failingRecheck.Seconds())
+		if params.ConsecutiveFailures >= healthFailureThreshold {
+			params.Status = healthStatusBroken
+		}
+		if params.Error == "" {
+			params.Error = fmt.Sprintf("destination returned %d", result.statusCode)
+		}
+	}
+	return params
+}
+
+// Notifies the owner and applies the action they opted in to
+func (cfg *apiCfg) handleBrokenLink(ctx context.Context, link database.ShortLink, reason string) {
+	cfg.emitLinkEvent(ctx, webhookLinkBroken, link)
+	var outcome string
+	switch link.HealthAction {
+	case healthActionDisable:
+		disabled, err := cfg.db.DisableUnhealthyShortLink(ctx, link.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to disable broken link: %v", err)
+			return
+		}
+		cfg.emitLinkEvent(ctx, webhookLinkToggled, disabled)
+		outcome = "We have switched the link off. Switch it back on once the page is fixed."
+	case healthActionFallback:
+		outcome = "Visitors are sent to your fallback URL " + link.HealthFallbackUrl + " until the page works again."
+	default:
+		return
+	}
+	user, err := cfg.db.RetrieveUserById(ctx, link.UserID)
+	if err != nil {
+		log.Printf("Failed to load link owner: %v", err)
+		return
+	}
+	body := fmt.Sprintf("Hi %s,\n\nThe destination of /%s (%s) failed %d checks in a row: %s\n\n%s",
+		user.Name, link.Slug, link.OriginalUrl, healthFailureThreshold, reason, outcome)
+	if err := cfg.mailer.Send(user.Email, "Broken link /"+link.Slug, body); err != nil {
+		log.Printf("Failed to send broken link email: %v", err)
+	}
+}
+
+// Whether visitors of the link should get its health fallback instead of the destination
+func (cfg *apiCfg) healthFallbackActive(ctx context.Context, link database.ShortLink) (bool, error) {
+	if !usesHealthFallback(link) {
+		return false, nil
+	}
+	health, err := cfg.db.GetLinkHealth(ctx, link.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return healthFallbackApplies(link, health), nil
+}
+
+func usesHealthFallback(link database.ShortLink) bool {
+	return link.HealthAction == healthActionFallback && link.HealthFallbackUrl != ""
+}
+
+func healthFallbackApplies(link database.ShortLink, health database.LinkHealth) bool {
+	return usesHealthFallback(link) && health.Status == healthStatusBroken
+}
+
+func linkHealthRes(link database.ShortLink, health database.LinkHealth) LinkHealthRes {
+	res := LinkHealthRes{
+		Status:              health.Status,
+		LatencyMs:           health.LatencyMs,
+		FinalURL:            health.FinalUrl,
+		Error:               health.Error,
+		TLSError:            health.TlsError,
+		ConsecutiveFailures: health.ConsecutiveFailures,
+		CheckedAt:           nullTimePtr(health.CheckedAt),
+		LastHealthyAt:       nullTimePtr(health.LastHealthyAt),
+		Action:              link.HealthAction,
+		FallbackURL:         link.HealthFallbackUrl,
+	}
+	if health.StatusCode.Valid {
+		res.StatusCode = &health.StatusCode.Int32
+	}
+	return res
+}
+
+func (cfg *apiCfg) GetLinkHealth(c *gin.Context) {
+	link, ok := cfg.ownedLinkFromParam(c)
+	if !ok {
+		return
+	}
+	health, err := cfg.db.GetLinkHealth(c, link.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		health = database.LinkHealth{Status: healthStatusUnknown}
+	} else if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, linkHealthRes(link, health))
+}
+
+// Moves the link to the front of the queue; the result shows up in its health shortly
+func (cfg *apiCfg) CheckLinkHealthNow(c *gin.Context) {
+	link, ok := cfg.ownedLinkFromParam(c)
+	if !ok {
+		return
+	}
+	if err := cfg.db.ScheduleLinkHealthCheck(c, link.ID); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, SuccessRes{Success: true})
+}
+
+// Nothing, disabling the link, or a fallback URL until it recovers
+func (cfg *apiCfg) UpdateLinkHealthAction(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	slug := c.Param("slug")
+	if slug == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	var data LinkHealthActionReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if !healthActions[data.Action] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be none, disable or fallback"})
+		return
+	}
+	data.FallbackURL = strings.TrimSpace(data.FallbackURL)
+	if data.Action == healthActionFallback {
+		if err := validateAbsoluteURL("fallback_url", data.FallbackURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		data.FallbackURL = ""
+	}
+	rows, err := cfg.db.UpdateShortLinkHealthAction(c, database.UpdateShortLinkHealthActionParams{
+		Slug:              slug,
+		UserID:            user.ID,
+		HealthAction:      data.Action,
+		HealthFallbackUrl: data.FallbackURL,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		return
+	}
+	link, err := cfg.db.RetrieveShortLinkBySlugNUserId(c, database.RetrieveShortLinkBySlugNUserIdParams{
+		UserID: user.ID,
+		Slug:   slug,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	// The fallback URL is somewhere visitors can be sent too
+	link = cfg.screenLinkOnSave(c, link)
+	cfg.emitLinkEvent(c, webhookLinkUpdated, link)
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
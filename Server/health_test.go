@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/google/uuid"
+)
+
+// A client for a local test server: its transport, with the health check redirect policy
+func testHealthClient(server *httptest.Server) *http.Client {
+	return &http.Client{
+		Transport:     server.Client().Transport,
+		Timeout:       linkHealthTimeout,
+		CheckRedirect: checkHealthRedirect,
+	}
+}
+
+// /hop/N redirects to /hop/0 in N requests past the first
+func newRedirectServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var hops int
+		if _, err := fmt.Sscanf(r.URL.Path, "/hop/%d", &hops); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if hops > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hop/%d", hops-1), http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheckDestinationStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantFailed bool
+	}{
+		{"/ok", http.StatusOK, false},
+		{"/get-only", http.StatusOK, false},
+		{"/busy", http.StatusTooManyRequests, false},
+		{"/missing", http.StatusNotFound, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result := checkDestination(context.Background(), testHealthClient(server), server.URL+tt.path)
+			if result.statusCode != tt.wantStatus || result.failed() != tt.wantFailed {
+				t.Fatalf("got status %d failed %v (%q), want %d failed %v", result.statusCode, result.failed(), result.err, tt.wantStatus, tt.wantFailed)
+			}
+			if result.finalURL != server.URL+tt.path {
+				t.Fatalf("final URL = %q, want %q", result.finalURL, server.URL+tt.path)
+			}
+		})
+	}
+}
+
+func TestCheckDestinationRedirects(t *testing.T) {
+	server := newRedirectServer(t)
+
+	result := checkDestination(context.Background(), testHealthClient(server), fmt.Sprintf("%s/hop/%d", server.URL, maxHealthRedirects-1))
+	if result.failed() || result.finalURL != server.URL+"/hop/0" {
+		t.Fatalf("got final URL %q err %q, want %s/hop/0", result.finalURL, result.err, server.URL)
+	}
+
+	result = checkDestination(context.Background(), testHealthClient(server), fmt.Sprintf("%s/hop/%d", server.URL, maxHealthRedirects))
+	if !result.failed() || !strings.Contains(result.err, "redirects") {
+		t.Fatalf("got status %d err %q, want a redirect limit failure", result.statusCode, result.err)
+	}
+}
+
+func TestCheckDestinationTLSError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// A client that doesn't trust the test server's self-signed certificate
+	client := &http.Client{Timeout: linkHealthTimeout, CheckRedirect: checkHealthRedirect}
+	result := checkDestination(context.Background(), client, server.URL)
+	if !result.failed() || result.tlsErr == "" {
+		t.Fatalf("got err %q tls %q, want a certificate error", result.err, result.tlsErr)
+	}
+}
+
+func TestHealthClientRefusesInternalAddresses(t *testing.T) {
+	var requests int
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer internal.Close()
+
+	result := checkDestination(context.Background(), newHealthClient(), internal.URL)
+	if result.err != errNonPublicAddress.Error() || result.statusCode != 0 || result.finalURL != "" {
+		t.Fatalf("got status %d final URL %q err %q, want only %q", result.statusCode, result.finalURL, result.err, errNonPublicAddress)
+	}
+	if requests != 0 {
+		t.Fatalf("internal server got %d requests, want none", requests)
+	}
+	if !errors.Is(publicDialControl("tcp", "169.254.169.254:80", nil), errNonPublicAddress) {
+		t.Fatal("metadata address was allowed")
+	}
+}
+
+func TestLinkHealthThreshold(t *testing.T) {
+	linkID := uuid.New()
+	failing := healthResult{statusCode: http.StatusNotFound}
+	healthy := healthResult{statusCode: http.StatusOK}
+	link := database.ShortLink{ID: linkID, HealthAction: healthActionFallback, HealthFallbackUrl: "https://example.com/sorry"}
+
+	health := database.LinkHealth{ShortLinkID: linkID, Status: healthStatusUnknown}
+	for check := 1; check <= healthFailureThreshold+1; check++ {
+		params := nextLinkHealth(linkID, health, failing)
+		wantStatus := healthStatusFailing
+		if check >= healthFailureThreshold {
+			wantStatus = healthStatusBroken
+		}
+		if params.Status != wantStatus || params.ConsecutiveFailures != int32(check) {
+			t.Fatalf("check %d: status %q failures %d, want %q %d", check, params.Status, params.ConsecutiveFailures, wantStatus, check)
+		}
+		// The broken action runs on exactly one check
+		if acted := params.ConsecutiveFailures == healthFailureThreshold; acted != (check == healthFailureThreshold) {
+			t.Fatalf("check %d: acted = %v", check, acted)
+		}
+		if params.Error != "destination returned 404" {
+			t.Fatalf("check %d: error %q", check, params.Error)
+		}
+		health = database.LinkHealth{ShortLinkID: linkID, Status: params.Status, ConsecutiveFailures: params.ConsecutiveFailures}
+		if got, want := healthFallbackApplies(link, health), check >= healthFailureThreshold; got != want {
+			t.Fatalf("check %d: fallback applies = %v, want %v", check, got, want)
+		}
+	}
+
+	params := nextLinkHealth(linkID, health, healthy)
+	if params.Status != healthStatusHealthy || params.ConsecutiveFailures != 0 {
+		t.Fatalf("after recovery: status %q failures %d, want healthy 0", params.Status, params.ConsecutiveFailures)
+	}
+	health = database.LinkHealth{Status: params.Status}
+	if healthFallbackApplies(link, health) {
+		t.Fatal("fallback still applies after recovery")
+	}
+
+	broken := database.LinkHealth{Status: healthStatusBroken}
+	if healthFallbackApplies(database.ShortLink{HealthAction: healthActionDisable}, broken) {
+		t.Fatal("fallback applies to a link that asked to be disabled")
+	}
+}
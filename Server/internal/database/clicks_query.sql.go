@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,35 +15,44 @@ import (
 
 const analyticsRetrieval = `-- name: AnalyticsRetrieval :many
 SELECT
-  clicks.id, clicks.short_link_id, clicks.ip_address, clicks.country, clicks.referrer, clicks.is_unique, clicks.utm_source, clicks.utm_medium, clicks.utm_campaign, clicks.created_at, 
+  clicks.id, clicks.short_link_id, clicks.ip_address, clicks.country, clicks.referrer, clicks.is_unique, clicks.utm_source, clicks.utm_medium, clicks.utm_campaign, clicks.created_at, clicks.referrer_domain, clicks.anonymized, clicks.referrer_source, clicks.referrer_channel, 
   devices.device_type, devices.platform, devices.language,
   devices.resolution, devices.timezone, devices.user_agent
 FROM clicks
 JOIN devices ON clicks.id = devices.click_id
-WHERE clicks.short_link_id = $1
+WHERE clicks.short_link_id = $1 AND clicks.created_at >= $2
 `
 
-type AnalyticsRetrievalRow struct {
-	ID          uuid.UUID
+type AnalyticsRetrievalParams struct {
 	ShortLinkID uuid.UUID
-	IpAddress   string
-	Country     string
-	Referrer    string
-	IsUnique    bool
-	UtmSource   string
-	UtmMedium   string
-	UtmCampaign string
 	CreatedAt   time.Time
-	DeviceType  string
-	Platform    string
-	Language    string
-	Resolution  string
-	Timezone    string
-	UserAgent   string
 }
 
-func (q *Queries) AnalyticsRetrieval(ctx context.Context, shortLinkID uuid.UUID) ([]AnalyticsRetrievalRow, error) {
-	rows, err := q.db.QueryContext(ctx, analyticsRetrieval, shortLinkID)
+type AnalyticsRetrievalRow struct {
+	ID              uuid.UUID
+	ShortLinkID     uuid.UUID
+	IpAddress       string
+	Country         string
+	Referrer        string
+	IsUnique        bool
+	UtmSource       string
+	UtmMedium       string
+	UtmCampaign     string
+	CreatedAt       time.Time
+	ReferrerDomain  string
+	Anonymized      bool
+	ReferrerSource  string
+	ReferrerChannel string
+	DeviceType      string
+	Platform        string
+	Language        string
+	Resolution      string
+	Timezone        string
+	UserAgent       string
+}
+
+func (q *Queries) AnalyticsRetrieval(ctx context.Context, arg AnalyticsRetrievalParams) ([]AnalyticsRetrievalRow, error) {
+	rows, err := q.db.QueryContext(ctx, analyticsRetrieval, arg.ShortLinkID, arg.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +71,10 @@ func (q *Queries) AnalyticsRetrieval(ctx context.Context, shortLinkID uuid.UUID)
 			&i.UtmMedium,
 			&i.UtmCampaign,
 			&i.CreatedAt,
+			&i.ReferrerDomain,
+			&i.Anonymized,
+			&i.ReferrerSource,
+			&i.ReferrerChannel,
 			&i.DeviceType,
 			&i.Platform,
 			&i.Language,
@@ -81,6 +95,302 @@ func (q *Queries) AnalyticsRetrieval(ctx context.Context, shortLinkID uuid.UUID)
 	return items, nil
 }
 
+const analyticsRetrievalByCampaign = `-- name: AnalyticsRetrievalByCampaign :many
+SELECT
+  clicks.id, clicks.short_link_id, clicks.ip_address, clicks.country, clicks.referrer, clicks.is_unique, clicks.utm_source, clicks.utm_medium, clicks.utm_campaign, clicks.created_at, clicks.referrer_domain, clicks.anonymized, clicks.referrer_source, clicks.referrer_channel,
+  devices.device_type, devices.platform, devices.language,
+  devices.resolution, devices.timezone, devices.user_agent
+FROM clicks
+JOIN devices ON clicks.id = devices.click_id
+JOIN short_links ON short_links.id = clicks.short_link_id
+WHERE short_links.campaign_id = $1 AND clicks.created_at >= $2
+`
+
+type AnalyticsRetrievalByCampaignParams struct {
+	CampaignID uuid.NullUUID
+	CreatedAt  time.Time
+}
+
+type AnalyticsRetrievalByCampaignRow struct {
+	ID              uuid.UUID
+	ShortLinkID     uuid.UUID
+	IpAddress       string
+	Country         string
+	Referrer        string
+	IsUnique        bool
+	UtmSource       string
+	UtmMedium       string
+	UtmCampaign     string
+	CreatedAt       time.Time
+	ReferrerDomain  string
+	Anonymized      bool
+	ReferrerSource  string
+	ReferrerChannel string
+	DeviceType      string
+	Platform        string
+	Language        string
+	Resolution      string
+	Timezone        string
+	UserAgent       string
+}
+
+func (q *Queries) AnalyticsRetrievalByCampaign(ctx context.Context, arg AnalyticsRetrievalByCampaignParams) ([]AnalyticsRetrievalByCampaignRow, error) {
+	rows, err := q.db.QueryContext(ctx, analyticsRetrievalByCampaign, arg.CampaignID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AnalyticsRetrievalByCampaignRow
+	for rows.Next() {
+		var i AnalyticsRetrievalByCampaignRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShortLinkID,
+			&i.IpAddress,
+			&i.Country,
+			&i.Referrer,
+			&i.IsUnique,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.CreatedAt,
+			&i.ReferrerDomain,
+			&i.Anonymized,
+			&i.ReferrerSource,
+			&i.ReferrerChannel,
+			&i.DeviceType,
+			&i.Platform,
+			&i.Language,
+			&i.Resolution,
+			&i.Timezone,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const analyticsRetrievalByFolder = `-- name: AnalyticsRetrievalByFolder :many
+WITH RECURSIVE folder_tree AS (
+  SELECT folders.id FROM folders
+  WHERE folders.id = $1
+  UNION ALL
+  SELECT folders.id FROM folders
+  JOIN folder_tree ON folders.parent_id = folder_tree.id
+)
+SELECT
+  clicks.id, clicks.short_link_id, clicks.ip_address, clicks.country, clicks.referrer, clicks.is_unique, clicks.utm_source, clicks.utm_medium, clicks.utm_campaign, clicks.created_at, clicks.referrer_domain, clicks.anonymized, clicks.referrer_source, clicks.referrer_channel,
+  devices.device_type, devices.platform, devices.language,
+  devices.resolution, devices.timezone, devices.user_agent
+FROM clicks
+JOIN devices ON clicks.id = devices.click_id
+JOIN short_links ON short_links.id = clicks.short_link_id
+WHERE short_links.folder_id IN (SELECT folder_tree.id FROM folder_tree)
+  AND clicks.created_at >= $2
+`
+
+type AnalyticsRetrievalByFolderParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+}
+
+type AnalyticsRetrievalByFolderRow struct {
+	ID              uuid.UUID
+	ShortLinkID     uuid.UUID
+	IpAddress       string
+	Country         string
+	Referrer        string
+	IsUnique        bool
+	UtmSource       string
+	UtmMedium       string
+	UtmCampaign     string
+	CreatedAt       time.Time
+	ReferrerDomain  string
+	Anonymized      bool
+	ReferrerSource  string
+	ReferrerChannel string
+	DeviceType      string
+	Platform        string
+	Language        string
+	Resolution      string
+	Timezone        string
+	UserAgent       string
+}
+
+func (q *Queries) AnalyticsRetrievalByFolder(ctx context.Context, arg AnalyticsRetrievalByFolderParams) ([]AnalyticsRetrievalByFolderRow, error) {
+	rows, err := q.db.QueryContext(ctx, analyticsRetrievalByFolder, arg.ID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AnalyticsRetrievalByFolderRow
+	for rows.Next() {
+		var i AnalyticsRetrievalByFolderRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShortLinkID,
+			&i.IpAddress,
+			&i.Country,
+			&i.Referrer,
+			&i.IsUnique,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.CreatedAt,
+			&i.ReferrerDomain,
+			&i.Anonymized,
+			&i.ReferrerSource,
+			&i.ReferrerChannel,
+			&i.DeviceType,
+			&i.Platform,
+			&i.Language,
+			&i.Resolution,
+			&i.Timezone,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const analyticsRetrievalByTag = `-- name: AnalyticsRetrievalByTag :many
+SELECT
+  clicks.id, clicks.short_link_id, clicks.ip_address, clicks.country, clicks.referrer, clicks.is_unique, clicks.utm_source, clicks.utm_medium, clicks.utm_campaign, clicks.created_at, clicks.referrer_domain, clicks.anonymized, clicks.referrer_source, clicks.referrer_channel,
+  devices.device_type, devices.platform, devices.language,
+  devices.resolution, devices.timezone, devices.user_agent
+FROM clicks
+JOIN devices ON clicks.id = devices.click_id
+JOIN short_link_tags ON short_link_tags.short_link_id = clicks.short_link_id
+WHERE short_link_tags.tag_id = $1 AND clicks.created_at >= $2
+`
+
+type AnalyticsRetrievalByTagParams struct {
+	TagID     uuid.UUID
+	CreatedAt time.Time
+}
+
+type AnalyticsRetrievalByTagRow struct {
+	ID              uuid.UUID
+	ShortLinkID     uuid.UUID
+	IpAddress       string
+	Country         string
+	Referrer        string
+	IsUnique        bool
+	UtmSource       string
+	UtmMedium       string
+	UtmCampaign     string
+	CreatedAt       time.Time
+	ReferrerDomain  string
+	Anonymized      bool
+	ReferrerSource  string
+	ReferrerChannel string
+	DeviceType      string
+	Platform        string
+	Language        string
+	Resolution      string
+	Timezone        string
+	UserAgent       string
+}
+
+func (q *Queries) AnalyticsRetrievalByTag(ctx context.Context, arg AnalyticsRetrievalByTagParams) ([]AnalyticsRetrievalByTagRow, error) {
+	rows, err := q.db.QueryContext(ctx, analyticsRetrievalByTag, arg.TagID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AnalyticsRetrievalByTagRow
+	for rows.Next() {
+		var i AnalyticsRetrievalByTagRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShortLinkID,
+			&i.IpAddress,
+			&i.Country,
+			&i.Referrer,
+			&i.IsUnique,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.CreatedAt,
+			&i.ReferrerDomain,
+			&i.Anonymized,
+			&i.ReferrerSource,
+			&i.ReferrerChannel,
+			&i.DeviceType,
+			&i.Platform,
+			&i.Language,
+			&i.Resolution,
+			&i.Timezone,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const anonymizeExpiredClicks = `-- name: AnonymizeExpiredClicks :execrows
+WITH expired AS (
+  SELECT clicks.id FROM clicks
+  JOIN short_links ON short_links.id = clicks.short_link_id
+  JOIN users ON users.id = short_links.user_id
+  WHERE NOT clicks.anonymized
+    AND clicks.created_at < (SELECT click_rollup_state.rolled_up_to FROM click_rollup_state)
+    AND clicks.created_at < LOCALTIMESTAMP - make_interval(days => COALESCE(users.click_retention_days, $1::int))
+  LIMIT $2::int
+), cleared AS (
+  UPDATE devices SET user_agent = ''
+  FROM expired
+  WHERE devices.click_id = expired.id
+)
+UPDATE clicks
+SET
+  ip_address = CASE
+    WHEN clicks.ip_address ~ '^[0-9]+(\.[0-9]+){3}$' THEN regexp_replace(clicks.ip_address, '[0-9]+$', '0')
+    WHEN clicks.ip_address ~ '^[0-9a-fA-F]*:[0-9a-fA-F:]*:[0-9a-fA-F:]*$' THEN host(set_masklen(clicks.ip_address::inet, 48)::cidr)
+    ELSE ''
+  END,
+  anonymized = TRUE
+FROM expired
+WHERE clicks.id = expired.id
+`
+
+type AnonymizeExpiredClicksParams struct {
+	DefaultDays int32
+	BatchSize   int32
+}
+
+func (q *Queries) AnonymizeExpiredClicks(ctx context.Context, arg AnonymizeExpiredClicksParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeExpiredClicks, arg.DefaultDays, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const countTotalClickByShortLinkId = `-- name: CountTotalClickByShortLinkId :one
 SELECT COUNT(id) FROM clicks
 WHERE short_link_id = $1
@@ -106,7 +416,7 @@ func (q *Queries) CountUniqueClickByShortLinkId(ctx context.Context, shortLinkID
 }
 
 const createClick = `-- name: CreateClick :one
-INSERT INTO clicks(id,short_link_id,ip_address,country,referrer,is_unique,utm_source,utm_medium,utm_campaign,created_at)
+INSERT INTO clicks(id,short_link_id,ip_address,country,referrer,is_unique,utm_source,utm_medium,utm_campaign,created_at,referrer_domain,referrer_source,referrer_channel)
 VALUES (
     gen_random_uuid(),
     $1,
@@ -117,19 +427,25 @@ VALUES (
     $6,
     $7,
     $8,
-    NOW()
+    NOW(),
+    $9,
+    $10,
+    $11
 )RETURNING id
 `
 
 type CreateClickParams struct {
-	ShortLinkID uuid.UUID
-	IpAddress   string
-	Country     string
-	Referrer    string
-	IsUnique    bool
-	UtmSource   string
-	UtmMedium   string
-	UtmCampaign string
+	ShortLinkID     uuid.UUID
+	IpAddress       string
+	Country         string
+	Referrer        string
+	IsUnique        bool
+	UtmSource       string
+	UtmMedium       string
+	UtmCampaign     string
+	ReferrerDomain  string
+	ReferrerSource  string
+	ReferrerChannel string
 }
 
 func (q *Queries) CreateClick(ctx context.Context, arg CreateClickParams) (uuid.UUID, error) {
@@ -142,14 +458,151 @@ func (q *Queries) CreateClick(ctx context.Context, arg CreateClickParams) (uuid.
 		arg.UtmSource,
 		arg.UtmMedium,
 		arg.UtmCampaign,
+		arg.ReferrerDomain,
+		arg.ReferrerSource,
+		arg.ReferrerChannel,
 	)
 	var id uuid.UUID
 	err := row.Scan(&id)
 	return id, err
 }
 
+const deleteExpiredClicks = `-- name: DeleteExpiredClicks :execrows
+DELETE FROM clicks
+WHERE clicks.id IN (
+  SELECT expired.id FROM clicks AS expired
+  JOIN short_links ON short_links.id = expired.short_link_id
+  JOIN users ON users.id = short_links.user_id
+  WHERE expired.created_at < (SELECT click_rollup_state.rolled_up_to FROM click_rollup_state)
+    AND expired.created_at < LOCALTIMESTAMP - make_interval(days => COALESCE(users.click_retention_days, $1::int))
+  LIMIT $2::int
+)
+`
+
+type DeleteExpiredClicksParams struct {
+	DefaultDays int32
+	BatchSize   int32
+}
+
+func (q *Queries) DeleteExpiredClicks(ctx context.Context, arg DeleteExpiredClicksParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredClicks, arg.DefaultDays, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const exportClicksByUserId = `-- name: ExportClicksByUserId :many
+SELECT
+  clicks.id, short_links.slug, clicks.created_at, clicks.ip_address, clicks.country, clicks.referrer,
+  clicks.is_unique, clicks.utm_source, clicks.utm_medium, clicks.utm_campaign,
+  COALESCE(devices.device_type, '')::text AS device_type,
+  COALESCE(devices.platform, '')::text AS platform,
+  COALESCE(devices.language, '')::text AS language,
+  COALESCE(devices.resolution, '')::text AS resolution,
+  COALESCE(devices.timezone, '')::text AS timezone,
+  COALESCE(devices.user_agent, '')::text AS user_agent
+FROM clicks
+LEFT JOIN devices ON clicks.id = devices.click_id
+JOIN short_links ON short_links.id = clicks.short_link_id
+WHERE short_links.user_id = $1
+  AND ($2::uuid IS NULL
+    OR (clicks.created_at, clicks.id) > ($3::timestamp, $2::uuid))
+ORDER BY clicks.created_at, clicks.id
+LIMIT $4::int
+`
+
+type ExportClicksByUserIdParams struct {
+	UserID    uuid.UUID
+	AfterID   uuid.NullUUID
+	AfterTime sql.NullTime
+	BatchSize int32
+}
+
+type ExportClicksByUserIdRow struct {
+	ID          uuid.UUID
+	Slug        string
+	CreatedAt   time.Time
+	IpAddress   string
+	Country     string
+	Referrer    string
+	IsUnique    bool
+	UtmSource   string
+	UtmMedium   string
+	UtmCampaign string
+	DeviceType  string
+	Platform    string
+	Language    string
+	Resolution  string
+	Timezone    string
+	UserAgent   string
+}
+
+func (q *Queries) ExportClicksByUserId(ctx context.Context, arg ExportClicksByUserIdParams) ([]ExportClicksByUserIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, exportClicksByUserId,
+		arg.UserID,
+		arg.AfterID,
+		arg.AfterTime,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportClicksByUserIdRow
+	for rows.Next() {
+		var i ExportClicksByUserIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Slug,
+			&i.CreatedAt,
+			&i.IpAddress,
+			&i.Country,
+			&i.Referrer,
+			&i.IsUnique,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.DeviceType,
+			&i.Platform,
+			&i.Language,
+			&i.Resolution,
+			&i.Timezone,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const notifyClick = `-- name: NotifyClick :exec
+SELECT pg_notify('link_clicks', $1::text)
+`
+
+func (q *Queries) NotifyClick(ctx context.Context, payload string) error {
+	_, err := q.db.ExecContext(ctx, notifyClick, payload)
+	return err
+}
+
+const notifyLiveWatch = `-- name: NotifyLiveWatch :exec
+SELECT pg_notify('link_clicks_watch', $1::text)
+`
+
+func (q *Queries) NotifyLiveWatch(ctx context.Context, payload string) error {
+	_, err := q.db.ExecContext(ctx, notifyLiveWatch, payload)
+	return err
+}
+
 const retrieveClicksById = `-- name: RetrieveClicksById :one
-SELECT id, short_link_id, ip_address, country, referrer, is_unique, utm_source, utm_medium, utm_campaign, created_at FROM clicks
+SELECT id, short_link_id, ip_address, country, referrer, is_unique, utm_source, utm_medium, utm_campaign, created_at, referrer_domain, anonymized, referrer_source, referrer_channel FROM clicks
 WHERE id = $1
 `
 
@@ -167,12 +620,16 @@ func (q *Queries) RetrieveClicksById(ctx context.Context, id uuid.UUID) (Click,
 		&i.UtmMedium,
 		&i.UtmCampaign,
 		&i.CreatedAt,
+		&i.ReferrerDomain,
+		&i.Anonymized,
+		&i.ReferrerSource,
+		&i.ReferrerChannel,
 	)
 	return i, err
 }
 
 const retrieveClicksByShortLinkId = `-- name: RetrieveClicksByShortLinkId :many
-SELECT id, short_link_id, ip_address, country, referrer, is_unique, utm_source, utm_medium, utm_campaign, created_at FROM clicks
+SELECT id, short_link_id, ip_address, country, referrer, is_unique, utm_source, utm_medium, utm_campaign, created_at, referrer_domain, anonymized, referrer_source, referrer_channel FROM clicks
 WHERE short_link_id = $1
 `
 
@@ -196,6 +653,10 @@ func (q *Queries) RetrieveClicksByShortLinkId(ctx context.Context, shortLinkID u
 			&i.UtmMedium,
 			&i.UtmCampaign,
 			&i.CreatedAt,
+			&i.ReferrerDomain,
+			&i.Anonymized,
+			&i.ReferrerSource,
+			&i.ReferrerChannel,
 		); err != nil {
 			return nil, err
 		}
@@ -6,22 +6,108 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-type Click struct {
+type AbuseReport struct {
+	ID             uuid.UUID
+	ShortLinkID    uuid.NullUUID
+	Slug           string
+	Category       string
+	Details        string
+	ReporterEmail  string
+	ReporterIpHash string
+	Status         string
+	ResolutionNote string
+	ResolvedBy     uuid.NullUUID
+	ResolvedAt     sql.NullTime
+	CreatedAt      time.Time
+}
+
+type AlertRule struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	ShortLinkID     uuid.UUID
+	Type            string
+	Threshold       int64
+	WindowMinutes   int32
+	Channel         string
+	IsMuted         bool
+	CheckedUntil    time.Time
+	LastTriggeredAt sql.NullTime
+	CreatedAt       time.Time
+}
+
+type AuditLog struct {
+	ID         uuid.UUID
+	ActorID    uuid.NullUUID
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	IpAddress  string
+	UserAgent  string
+	CreatedAt  time.Time
+	AccountID  uuid.NullUUID
+}
+
+type Campaign struct {
 	ID          uuid.UUID
-	ShortLinkID uuid.UUID
-	IpAddress   string
-	Country     string
-	Referrer    string
-	IsUnique    bool
+	UserID      uuid.UUID
+	Name        string
 	UtmSource   string
 	UtmMedium   string
 	UtmCampaign string
+	StartsAt    sql.NullTime
+	EndsAt      sql.NullTime
+	BudgetNote  string
 	CreatedAt   time.Time
+	UpdatedAt   sql.NullTime
+}
+
+type Click struct {
+	ID              uuid.UUID
+	ShortLinkID     uuid.UUID
+	IpAddress       string
+	Country         string
+	Referrer        string
+	IsUnique        bool
+	UtmSource       string
+	UtmMedium       string
+	UtmCampaign     string
+	CreatedAt       time.Time
+	ReferrerDomain  string
+	Anonymized      bool
+	ReferrerSource  string
+	ReferrerChannel string
+}
+
+type ClickDailyRollup struct {
+	ShortLinkID  uuid.UUID
+	Bucket       time.Time
+	Dimension    string
+	Value        string
+	Clicks       int64
+	UniqueClicks int64
+}
+
+type ClickHourlyRollup struct {
+	ShortLinkID  uuid.UUID
+	Bucket       time.Time
+	Dimension    string
+	Value        string
+	Clicks       int64
+	UniqueClicks int64
+}
+
+type ClickRollupState struct {
+	ID         bool
+	RolledUpTo time.Time
+	UpdatedAt  time.Time
 }
 
 type Device struct {
@@ -36,17 +122,146 @@ type Device struct {
 	CreatedAt  time.Time
 }
 
+type EmailToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	Purpose   string
+	NewEmail  string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type FallbackPage struct {
+	UserID     uuid.UUID
+	Kind       string
+	Action     string
+	Url        string
+	Template   string
+	StatusCode int32
+	Title      string
+	Message    string
+	CreatedAt  time.Time
+	UpdatedAt  sql.NullTime
+}
+
+type Folder struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ParentID  uuid.NullUUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt sql.NullTime
+}
+
+type LinkHealth struct {
+	ShortLinkID         uuid.UUID
+	Status              string
+	StatusCode          sql.NullInt32
+	LatencyMs           int32
+	FinalUrl            string
+	Error               string
+	TlsError            string
+	ConsecutiveFailures int32
+	CheckedAt           sql.NullTime
+	LastHealthyAt       sql.NullTime
+	NextCheckAt         time.Time
+}
+
+type LinkSchedule struct {
+	ShortLinkID    uuid.UUID
+	Timezone       string
+	ActivateAt     sql.NullTime
+	DeactivateAt   sql.NullTime
+	Windows        json.RawMessage
+	FallbackUrl    string
+	ExpiryNotified bool
+	CreatedAt      time.Time
+	UpdatedAt      sql.NullTime
+}
+
+type LoginAttempt struct {
+	ID        uuid.UUID
+	UserID    uuid.NullUUID
+	Email     string
+	IpAddress string
+	UserAgent string
+	Success   bool
+	CreatedAt time.Time
+}
+
+type MfaRecoveryCode struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CodeHash  string
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type OidcState struct {
+	ID           uuid.UUID
+	StateHash    string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+type QuarantinedSlug struct {
+	Slug       string
+	ReleasedAt time.Time
+	CreatedAt  time.Time
+}
+
+type ReferrerSource struct {
+	Domain  string
+	Name    string
+	Channel string
+}
+
 type ShortLink struct {
-	ID          uuid.UUID
-	UserID      uuid.UUID
-	Slug        string
-	OriginalUrl string
-	UtmSource   string
-	UtmMedium   string
-	UtmCampaign string
-	IsActive    sql.NullBool
-	CreatedAt   time.Time
-	UpdatedAt   sql.NullTime
+	ID                uuid.UUID
+	UserID            uuid.UUID
+	Slug              string
+	OriginalUrl       string
+	UtmSource         string
+	UtmMedium         string
+	UtmCampaign       string
+	IsActive          sql.NullBool
+	CreatedAt         time.Time
+	UpdatedAt         sql.NullTime
+	Title             string
+	FolderID          uuid.NullUUID
+	CampaignID        uuid.NullUUID
+	UtmTerm           string
+	UtmContent        string
+	ExtraParams       json.RawMessage
+	UtmPolicy         string
+	PassQuery         bool
+	PrivacyMode       string
+	HealthAction      string
+	HealthFallbackUrl string
+	QuarantinedAt     sql.NullTime
+	QuarantineReason  string
+	QuarantinedBy     string
+	BlockedAt         sql.NullTime
+	BlockedReason     string
+	DeletedAt         sql.NullTime
+}
+
+type ShortLinkTag struct {
+	ShortLinkID uuid.UUID
+	TagID       uuid.UUID
+}
+
+type Tag struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	Color     string
+	CreatedAt time.Time
 }
 
 type Token struct {
@@ -59,10 +274,55 @@ type Token struct {
 }
 
 type User struct {
+	ID                  uuid.UUID
+	Name                string
+	Email               string
+	Password            string
+	CreatedAt           time.Time
+	UpdatedAt           sql.NullTime
+	EmailVerified       bool
+	MfaEnabled          bool
+	MfaSecret           string
+	MfaLastUsedStep     int64
+	TokensValidAfter    int64
+	ClickRetentionDays  sql.NullInt32
+	DeletionScheduledAt sql.NullTime
+	PrivacyMode         string
+	Role                string
+	SuspendedAt         sql.NullTime
+	SuspensionReason    string
+}
+
+type UserIdentity struct {
 	ID        uuid.UUID
-	Name      string
+	UserID    uuid.UUID
+	Provider  string
+	Subject   string
 	Email     string
-	Password  string
+	CreatedAt time.Time
+}
+
+type Webhook struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Url       string
+	Secret    string
+	Events    []string
+	IsActive  bool
 	CreatedAt time.Time
 	UpdatedAt sql.NullTime
 }
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	Event          string
+	Payload        string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  sql.NullTime
+	ResponseStatus sql.NullInt32
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    sql.NullTime
+}
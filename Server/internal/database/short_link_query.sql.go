@@ -7,12 +7,106 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const createShortLink = `-- name: CreateShortLink :exec
-INSERT INTO short_links(id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign,is_active,created_at)
+const attachShortLinksToCampaign = `-- name: AttachShortLinksToCampaign :many
+UPDATE short_links
+SET campaign_id = $1,
+  utm_source = CASE WHEN short_links.utm_source = '' THEN $2::text ELSE short_links.utm_source END,
+  utm_medium = CASE WHEN short_links.utm_medium = '' THEN $3::text ELSE short_links.utm_medium END,
+  utm_campaign = CASE WHEN short_links.utm_campaign = '' THEN $4::text ELSE short_links.utm_campaign END,
+  updated_at = NOW()
+WHERE user_id = $5 AND slug = ANY($6::text[]) AND deleted_at IS NULL
+RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at
+`
+
+type AttachShortLinksToCampaignParams struct {
+	CampaignID  uuid.NullUUID
+	UtmSource   string
+	UtmMedium   string
+	UtmCampaign string
+	UserID      uuid.UUID
+	Slugs       []string
+}
+
+func (q *Queries) AttachShortLinksToCampaign(ctx context.Context, arg AttachShortLinksToCampaignParams) ([]ShortLink, error) {
+	rows, err := q.db.QueryContext(ctx, attachShortLinksToCampaign,
+		arg.CampaignID,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.UserID,
+		pq.Array(arg.Slugs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShortLink
+	for rows.Next() {
+		var i ShortLink
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Slug,
+			&i.OriginalUrl,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.FolderID,
+			&i.CampaignID,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.ExtraParams,
+			&i.UtmPolicy,
+			&i.PassQuery,
+			&i.PrivacyMode,
+			&i.HealthAction,
+			&i.HealthFallbackUrl,
+			&i.QuarantinedAt,
+			&i.QuarantineReason,
+			&i.QuarantinedBy,
+			&i.BlockedAt,
+			&i.BlockedReason,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countShortLinksByCampaignId = `-- name: CountShortLinksByCampaignId :one
+SELECT COUNT(id) FROM short_links
+WHERE campaign_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountShortLinksByCampaignId(ctx context.Context, campaignID uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countShortLinksByCampaignId, campaignID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createShortLink = `-- name: CreateShortLink :one
+INSERT INTO short_links(id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign,is_active,created_at,title,campaign_id,utm_term,utm_content,extra_params,utm_policy,pass_query,privacy_mode)
 VALUES(
     gen_random_uuid(),
     $1,
@@ -22,8 +116,16 @@ VALUES(
     $5,
     $6,
     TRUE,
-    NOW()
-) RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at
+    NOW(),
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    $13,
+    $14
+) RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at
 `
 
 type CreateShortLinkParams struct {
@@ -33,37 +135,681 @@ type CreateShortLinkParams struct {
 	UtmSource   string
 	UtmMedium   string
 	UtmCampaign string
+	Title       string
+	CampaignID  uuid.NullUUID
+	UtmTerm     string
+	UtmContent  string
+	ExtraParams json.RawMessage
+	UtmPolicy   string
+	PassQuery   bool
+	PrivacyMode string
 }
 
-func (q *Queries) CreateShortLink(ctx context.Context, arg CreateShortLinkParams) error {
-	_, err := q.db.ExecContext(ctx, createShortLink,
+func (q *Queries) CreateShortLink(ctx context.Context, arg CreateShortLinkParams) (ShortLink, error) {
+	row := q.db.QueryRowContext(ctx, createShortLink,
 		arg.UserID,
 		arg.Slug,
 		arg.OriginalUrl,
 		arg.UtmSource,
 		arg.UtmMedium,
 		arg.UtmCampaign,
+		arg.Title,
+		arg.CampaignID,
+		arg.UtmTerm,
+		arg.UtmContent,
+		arg.ExtraParams,
+		arg.UtmPolicy,
+		arg.PassQuery,
+		arg.PrivacyMode,
 	)
-	return err
+	var i ShortLink
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Slug,
+		&i.OriginalUrl,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
+	)
+	return i, err
 }
 
-const deleteShortLinkBySlugNUserId = `-- name: DeleteShortLinkBySlugNUserId :exec
+const deleteTrashedShortLinkBySlugNUserId = `-- name: DeleteTrashedShortLinkBySlugNUserId :one
 DELETE FROM short_links
-WHERE slug = $1 AND user_id = $2
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at
 `
 
-type DeleteShortLinkBySlugNUserIdParams struct {
+type DeleteTrashedShortLinkBySlugNUserIdParams struct {
 	Slug   string
 	UserID uuid.UUID
 }
 
-func (q *Queries) DeleteShortLinkBySlugNUserId(ctx context.Context, arg DeleteShortLinkBySlugNUserIdParams) error {
-	_, err := q.db.ExecContext(ctx, deleteShortLinkBySlugNUserId, arg.Slug, arg.UserID)
-	return err
+func (q *Queries) DeleteTrashedShortLinkBySlugNUserId(ctx context.Context, arg DeleteTrashedShortLinkBySlugNUserIdParams) (ShortLink, error) {
+	row := q.db.QueryRowContext(ctx, deleteTrashedShortLinkBySlugNUserId, arg.Slug, arg.UserID)
+	var i ShortLink
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Slug,
+		&i.OriginalUrl,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const exportShortLinksByUserId = `-- name: ExportShortLinksByUserId :many
+SELECT
+  short_links.id, short_links.user_id, short_links.slug, short_links.original_url, short_links.utm_source, short_links.utm_medium, short_links.utm_campaign, short_links.is_active, short_links.created_at, short_links.updated_at, short_links.title, short_links.folder_id, short_links.campaign_id, short_links.utm_term, short_links.utm_content, short_links.extra_params, short_links.utm_policy, short_links.pass_query, short_links.privacy_mode, short_links.health_action, short_links.health_fallback_url, short_links.quarantined_at, short_links.quarantine_reason, short_links.quarantined_by, short_links.blocked_at, short_links.blocked_reason, short_links.deleted_at,
+  COALESCE(link_tags.tags, '{}')::TEXT[] AS tags
+FROM short_links
+CROSS JOIN LATERAL (
+  SELECT array_agg(tags.name ORDER BY tags.name) AS tags
+  FROM short_link_tags
+  JOIN tags ON tags.id = short_link_tags.tag_id
+  WHERE short_link_tags.short_link_id = short_links.id
+) link_tags
+WHERE short_links.user_id = $1
+ORDER BY short_links.created_at
+`
+
+type ExportShortLinksByUserIdRow struct {
+	ID                uuid.UUID
+	UserID            uuid.UUID
+	Slug              string
+	OriginalUrl       string
+	UtmSource         string
+	UtmMedium         string
+	UtmCampaign       string
+	IsActive          sql.NullBool
+	CreatedAt         time.Time
+	UpdatedAt         sql.NullTime
+	Title             string
+	FolderID          uuid.NullUUID
+	CampaignID        uuid.NullUUID
+	UtmTerm           string
+	UtmContent        string
+	ExtraParams       json.RawMessage
+	UtmPolicy         string
+	PassQuery         bool
+	PrivacyMode       string
+	HealthAction      string
+	HealthFallbackUrl string
+	QuarantinedAt     sql.NullTime
+	QuarantineReason  string
+	QuarantinedBy     string
+	BlockedAt         sql.NullTime
+	BlockedReason     string
+	DeletedAt         sql.NullTime
+	Tags              []string
+}
+
+func (q *Queries) ExportShortLinksByUserId(ctx context.Context, userID uuid.UUID) ([]ExportShortLinksByUserIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, exportShortLinksByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportShortLinksByUserIdRow
+	for rows.Next() {
+		var i ExportShortLinksByUserIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Slug,
+			&i.OriginalUrl,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.FolderID,
+			&i.CampaignID,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.ExtraParams,
+			&i.UtmPolicy,
+			&i.PassQuery,
+			&i.PrivacyMode,
+			&i.HealthAction,
+			&i.HealthFallbackUrl,
+			&i.QuarantinedAt,
+			&i.QuarantineReason,
+			&i.QuarantinedBy,
+			&i.BlockedAt,
+			&i.BlockedReason,
+			&i.DeletedAt,
+			pq.Array(&i.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShortLinksWithStats = `-- name: ListShortLinksWithStats :many
+WITH RECURSIVE folder_tree AS (
+  SELECT folders.id FROM folders
+  WHERE folders.id = $1::uuid AND folders.user_id = $2
+  UNION ALL
+  SELECT folders.id FROM folders
+  JOIN folder_tree ON folders.parent_id = folder_tree.id
+), matches AS (
+  -- Clicks are only counted here when sorting by them; the rest is joined after the page is cut
+  SELECT short_links.id, short_links.created_at, click_totals.total_clicks
+  FROM short_links
+  CROSS JOIN click_rollup_state
+  LEFT JOIN link_health ON link_health.short_link_id = short_links.id
+  CROSS JOIN LATERAL (
+    SELECT COALESCE(SUM(counts.total_clicks), 0)::BIGINT AS total_clicks
+    FROM (
+      SELECT click_hourly_rollups.clicks AS total_clicks
+      FROM click_hourly_rollups
+      WHERE click_hourly_rollups.short_link_id = short_links.id AND click_hourly_rollups.dimension = ''
+        AND click_hourly_rollups.bucket < click_rollup_state.rolled_up_to AND $3::text IN ('clicks_asc', 'clicks_desc')
+      UNION ALL
+      SELECT 1
+      FROM clicks
+      WHERE clicks.short_link_id = short_links.id AND clicks.created_at >= click_rollup_state.rolled_up_to
+        AND $3::text IN ('clicks_asc', 'clicks_desc')
+    ) counts
+  ) click_totals
+  WHERE short_links.user_id = $2 AND short_links.deleted_at IS NULL
+    AND ($4::text IS NULL
+      OR to_tsvector('simple', short_links.slug || ' ' || short_links.original_url || ' ' || short_links.title) @@ websearch_to_tsquery('simple', $4::text)
+      OR short_links.slug ILIKE replace(replace(replace($4::text, '\', '\\'), '%', '\%'), '_', '\_') || '%')
+    AND ($5::boolean IS NULL OR short_links.is_active = $5::boolean)
+    AND ($6::text IS NULL OR short_links.utm_campaign = $6::text)
+    AND ($7::timestamp IS NULL OR short_links.created_at >= $7::timestamp)
+    AND ($8::timestamp IS NULL OR short_links.created_at < $8::timestamp)
+    AND ($9::uuid IS NULL OR EXISTS (
+      SELECT 1 FROM short_link_tags
+      WHERE short_link_tags.short_link_id = short_links.id AND short_link_tags.tag_id = $9::uuid))
+    AND ($1::uuid IS NULL OR short_links.folder_id IN (SELECT folder_tree.id FROM folder_tree))
+    AND ($10::uuid IS NULL OR short_links.campaign_id = $10::uuid)
+    AND ($11::text IS NULL OR COALESCE(link_health.status, 'unknown') = $11::text)
+), page AS (
+  SELECT matches.id FROM matches
+  WHERE $12::uuid IS NULL OR CASE $3::text
+      WHEN 'created_asc' THEN (matches.created_at, matches.id) > ($13::timestamp, $12::uuid)
+      WHEN 'clicks_asc' THEN (matches.total_clicks, matches.id) > ($14::bigint, $12::uuid)
+      WHEN 'clicks_desc' THEN (matches.total_clicks, matches.id) < ($14::bigint, $12::uuid)
+      WHEN 'created_desc' THEN (matches.created_at, matches.id) < ($13::timestamp, $12::uuid)
+    END
+  ORDER BY
+    CASE WHEN $3::text = 'created_asc' THEN matches.created_at END ASC,
+    CASE WHEN $3::text = 'created_desc' THEN matches.created_at END DESC,
+    CASE WHEN $3::text = 'clicks_asc' THEN matches.total_clicks END ASC,
+    CASE WHEN $3::text = 'clicks_desc' THEN matches.total_clicks END DESC,
+    CASE WHEN $3::text IN ('created_asc', 'clicks_asc') THEN matches.id END ASC,
+    CASE WHEN $3::text IN ('created_desc', 'clicks_desc') THEN matches.id END DESC
+  LIMIT $15::int
+), links AS (
+  SELECT
+    short_links.id, short_links.user_id, short_links.slug, short_links.original_url, short_links.utm_source, short_links.utm_medium, short_links.utm_campaign, short_links.is_active, short_links.created_at, short_links.updated_at, short_links.title, short_links.folder_id, short_links.campaign_id, short_links.utm_term, short_links.utm_content, short_links.extra_params, short_links.utm_policy, short_links.pass_query, short_links.privacy_mode, short_links.health_action, short_links.health_fallback_url, short_links.quarantined_at, short_links.quarantine_reason, short_links.quarantined_by, short_links.blocked_at, short_links.blocked_reason, short_links.deleted_at,
+    stats.total_clicks::BIGINT AS total_clicks,
+    stats.unique_clicks::BIGINT AS unique_clicks,
+    COALESCE(link_tags.tags, '{}')::TEXT[] AS tags,
+    COALESCE(link_health.status, 'unknown')::TEXT AS health_status,
+    link_health.status_code AS health_status_code,
+    link_health.checked_at AS health_checked_at
+  FROM page
+  JOIN short_links ON short_links.id = page.id
+  CROSS JOIN click_rollup_state
+  LEFT JOIN link_health ON link_health.short_link_id = short_links.id
+  CROSS JOIN LATERAL (
+    -- Raw clicks behind the watermark may be gone after retention, so they come from the rollups
+    SELECT COALESCE(SUM(counts.total_clicks), 0) AS total_clicks, COALESCE(SUM(counts.unique_clicks), 0) AS unique_clicks
+    FROM (
+      SELECT click_hourly_rollups.clicks AS total_clicks, click_hourly_rollups.unique_clicks
+      FROM click_hourly_rollups
+      WHERE click_hourly_rollups.short_link_id = short_links.id AND click_hourly_rollups.dimension = ''
+        AND click_hourly_rollups.bucket < click_rollup_state.rolled_up_to
+      UNION ALL
+      SELECT 1, CASE WHEN clicks.is_unique THEN 1 ELSE 0 END
+      FROM clicks
+      WHERE clicks.short_link_id = short_links.id AND clicks.created_at >= click_rollup_state.rolled_up_to
+    ) counts
+  ) stats
+  CROSS JOIN LATERAL (
+    SELECT array_agg(tags.name ORDER BY tags.name) AS tags
+    FROM short_link_tags
+    JOIN tags ON tags.id = short_link_tags.tag_id
+    WHERE short_link_tags.short_link_id = short_links.id
+  ) link_tags
+)
+SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at, total_clicks, unique_clicks, tags, health_status, health_status_code, health_checked_at FROM links
+ORDER BY
+  CASE WHEN $3::text = 'created_asc' THEN links.created_at END ASC,
+  CASE WHEN $3::text = 'created_desc' THEN links.created_at END DESC,
+  CASE WHEN $3::text = 'clicks_asc' THEN links.total_clicks END ASC,
+  CASE WHEN $3::text = 'clicks_desc' THEN links.total_clicks END DESC,
+  CASE WHEN $3::text IN ('created_asc', 'clicks_asc') THEN links.id END ASC,
+  CASE WHEN $3::text IN ('created_desc', 'clicks_desc') THEN links.id END DESC
+`
+
+type ListShortLinksWithStatsParams struct {
+	FolderID      uuid.NullUUID
+	UserID        uuid.UUID
+	Sort          string
+	Search        sql.NullString
+	IsActive      sql.NullBool
+	Campaign      sql.NullString
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	TagID         uuid.NullUUID
+	CampaignID    uuid.NullUUID
+	Health        sql.NullString
+	CursorID      uuid.NullUUID
+	CursorTime    sql.NullTime
+	CursorClicks  sql.NullInt64
+	PageLimit     int32
+}
+
+type ListShortLinksWithStatsRow struct {
+	ID                uuid.UUID
+	UserID            uuid.UUID
+	Slug              string
+	OriginalUrl       string
+	UtmSource         string
+	UtmMedium         string
+	UtmCampaign       string
+	IsActive          sql.NullBool
+	CreatedAt         time.Time
+	UpdatedAt         sql.NullTime
+	Title             string
+	FolderID          uuid.NullUUID
+	CampaignID        uuid.NullUUID
+	UtmTerm           string
+	UtmContent        string
+	ExtraParams       json.RawMessage
+	UtmPolicy         string
+	PassQuery         bool
+	PrivacyMode       string
+	HealthAction      string
+	HealthFallbackUrl string
+	QuarantinedAt     sql.NullTime
+	QuarantineReason  string
+	QuarantinedBy     string
+	BlockedAt         sql.NullTime
+	BlockedReason     string
+	DeletedAt         sql.NullTime
+	TotalClicks       int64
+	UniqueClicks      int64
+	Tags              []string
+	HealthStatus      string
+	HealthStatusCode  sql.NullInt32
+	HealthCheckedAt   sql.NullTime
+}
+
+func (q *Queries) ListShortLinksWithStats(ctx context.Context, arg ListShortLinksWithStatsParams) ([]ListShortLinksWithStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listShortLinksWithStats,
+		arg.FolderID,
+		arg.UserID,
+		arg.Sort,
+		arg.Search,
+		arg.IsActive,
+		arg.Campaign,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.TagID,
+		arg.CampaignID,
+		arg.Health,
+		arg.CursorID,
+		arg.CursorTime,
+		arg.CursorClicks,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListShortLinksWithStatsRow
+	for rows.Next() {
+		var i ListShortLinksWithStatsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Slug,
+			&i.OriginalUrl,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.FolderID,
+			&i.CampaignID,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.ExtraParams,
+			&i.UtmPolicy,
+			&i.PassQuery,
+			&i.PrivacyMode,
+			&i.HealthAction,
+			&i.HealthFallbackUrl,
+			&i.QuarantinedAt,
+			&i.QuarantineReason,
+			&i.QuarantinedBy,
+			&i.BlockedAt,
+			&i.BlockedReason,
+			&i.DeletedAt,
+			&i.TotalClicks,
+			&i.UniqueClicks,
+			pq.Array(&i.Tags),
+			&i.HealthStatus,
+			&i.HealthStatusCode,
+			&i.HealthCheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedShortLinksByUserId = `-- name: ListTrashedShortLinksByUserId :many
+SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at FROM short_links
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+LIMIT $2::int OFFSET $3::int
+`
+
+type ListTrashedShortLinksByUserIdParams struct {
+	UserID     uuid.UUID
+	PageLimit  int32
+	PageOffset int32
+}
+
+func (q *Queries) ListTrashedShortLinksByUserId(ctx context.Context, arg ListTrashedShortLinksByUserIdParams) ([]ShortLink, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedShortLinksByUserId, arg.UserID, arg.PageLimit, arg.PageOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShortLink
+	for rows.Next() {
+		var i ShortLink
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Slug,
+			&i.OriginalUrl,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.FolderID,
+			&i.CampaignID,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.ExtraParams,
+			&i.UtmPolicy,
+			&i.PassQuery,
+			&i.PrivacyMode,
+			&i.HealthAction,
+			&i.HealthFallbackUrl,
+			&i.QuarantinedAt,
+			&i.QuarantineReason,
+			&i.QuarantinedBy,
+			&i.BlockedAt,
+			&i.BlockedReason,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveShortLinksToFolder = `-- name: MoveShortLinksToFolder :many
+UPDATE short_links
+SET folder_id = $1, updated_at = NOW()
+WHERE user_id = $2 AND slug = ANY($3::text[]) AND deleted_at IS NULL
+RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at
+`
+
+type MoveShortLinksToFolderParams struct {
+	FolderID uuid.NullUUID
+	UserID   uuid.UUID
+	Slugs    []string
+}
+
+func (q *Queries) MoveShortLinksToFolder(ctx context.Context, arg MoveShortLinksToFolderParams) ([]ShortLink, error) {
+	rows, err := q.db.QueryContext(ctx, moveShortLinksToFolder, arg.FolderID, arg.UserID, pq.Array(arg.Slugs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShortLink
+	for rows.Next() {
+		var i ShortLink
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Slug,
+			&i.OriginalUrl,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.FolderID,
+			&i.CampaignID,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.ExtraParams,
+			&i.UtmPolicy,
+			&i.PassQuery,
+			&i.PrivacyMode,
+			&i.HealthAction,
+			&i.HealthFallbackUrl,
+			&i.QuarantinedAt,
+			&i.QuarantineReason,
+			&i.QuarantinedBy,
+			&i.BlockedAt,
+			&i.BlockedReason,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeTrashedShortLinks = `-- name: PurgeTrashedShortLinks :many
+DELETE FROM short_links
+WHERE id IN (
+  SELECT id FROM short_links
+  WHERE deleted_at < NOW() - make_interval(days => $1::int)
+  LIMIT $2::int
+)
+RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at
+`
+
+type PurgeTrashedShortLinksParams struct {
+	TrashDays int32
+	BatchSize int32
+}
+
+func (q *Queries) PurgeTrashedShortLinks(ctx context.Context, arg PurgeTrashedShortLinksParams) ([]ShortLink, error) {
+	rows, err := q.db.QueryContext(ctx, purgeTrashedShortLinks, arg.TrashDays, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShortLink
+	for rows.Next() {
+		var i ShortLink
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Slug,
+			&i.OriginalUrl,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.FolderID,
+			&i.CampaignID,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.ExtraParams,
+			&i.UtmPolicy,
+			&i.PassQuery,
+			&i.PrivacyMode,
+			&i.HealthAction,
+			&i.HealthFallbackUrl,
+			&i.QuarantinedAt,
+			&i.QuarantineReason,
+			&i.QuarantinedBy,
+			&i.BlockedAt,
+			&i.BlockedReason,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreShortLinkBySlugNUserId = `-- name: RestoreShortLinkBySlugNUserId :one
+UPDATE short_links
+SET deleted_at = NULL, updated_at = NOW()
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at
+`
+
+type RestoreShortLinkBySlugNUserIdParams struct {
+	Slug   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) RestoreShortLinkBySlugNUserId(ctx context.Context, arg RestoreShortLinkBySlugNUserIdParams) (ShortLink, error) {
+	row := q.db.QueryRowContext(ctx, restoreShortLinkBySlugNUserId, arg.Slug, arg.UserID)
+	var i ShortLink
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Slug,
+		&i.OriginalUrl,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
+	)
+	return i, err
 }
 
 const retrieveShortLinkById = `-- name: RetrieveShortLinkById :one
-SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at FROM short_links
+SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at FROM short_links
 WHERE id = $1
 `
 
@@ -81,13 +827,30 @@ func (q *Queries) RetrieveShortLinkById(ctx context.Context, id uuid.UUID) (Shor
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const retrieveShortLinkBySlug = `-- name: RetrieveShortLinkBySlug :one
-SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at FROM short_links
-WHERE slug = $1
+SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at FROM short_links
+WHERE slug = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) RetrieveShortLinkBySlug(ctx context.Context, slug string) (ShortLink, error) {
@@ -104,13 +867,30 @@ func (q *Queries) RetrieveShortLinkBySlug(ctx context.Context, slug string) (Sho
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const retrieveShortLinkBySlugNUserId = `-- name: RetrieveShortLinkBySlugNUserId :one
-SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at FROM short_links
-WHERE slug = $1 AND user_id = $2
+SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at FROM short_links
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NULL
 `
 
 type RetrieveShortLinkBySlugNUserIdParams struct {
@@ -132,13 +912,115 @@ func (q *Queries) RetrieveShortLinkBySlugNUserId(ctx context.Context, arg Retrie
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const retrieveShortLinkBySlugNUserIdWithTrashed = `-- name: RetrieveShortLinkBySlugNUserIdWithTrashed :one
+SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at FROM short_links
+WHERE slug = $1 AND user_id = $2
+`
+
+type RetrieveShortLinkBySlugNUserIdWithTrashedParams struct {
+	Slug   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) RetrieveShortLinkBySlugNUserIdWithTrashed(ctx context.Context, arg RetrieveShortLinkBySlugNUserIdWithTrashedParams) (ShortLink, error) {
+	row := q.db.QueryRowContext(ctx, retrieveShortLinkBySlugNUserIdWithTrashed, arg.Slug, arg.UserID)
+	var i ShortLink
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Slug,
+		&i.OriginalUrl,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const retrieveShortLinkBySlugWithTrashed = `-- name: RetrieveShortLinkBySlugWithTrashed :one
+SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at FROM short_links
+WHERE slug = $1
+`
+
+func (q *Queries) RetrieveShortLinkBySlugWithTrashed(ctx context.Context, slug string) (ShortLink, error) {
+	row := q.db.QueryRowContext(ctx, retrieveShortLinkBySlugWithTrashed, slug)
+	var i ShortLink
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Slug,
+		&i.OriginalUrl,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const retrieveShortLinkByUserId = `-- name: RetrieveShortLinkByUserId :many
-SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at FROM short_links
-WHERE user_id = $1
+SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at FROM short_links
+WHERE user_id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) RetrieveShortLinkByUserId(ctx context.Context, userID uuid.UUID) ([]ShortLink, error) {
@@ -161,6 +1043,23 @@ func (q *Queries) RetrieveShortLinkByUserId(ctx context.Context, userID uuid.UUI
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Title,
+			&i.FolderID,
+			&i.CampaignID,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.ExtraParams,
+			&i.UtmPolicy,
+			&i.PassQuery,
+			&i.PrivacyMode,
+			&i.HealthAction,
+			&i.HealthFallbackUrl,
+			&i.QuarantinedAt,
+			&i.QuarantineReason,
+			&i.QuarantinedBy,
+			&i.BlockedAt,
+			&i.BlockedReason,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -176,8 +1075,8 @@ func (q *Queries) RetrieveShortLinkByUserId(ctx context.Context, userID uuid.UUI
 }
 
 const retrieveShortLinkByUserIdANDId = `-- name: RetrieveShortLinkByUserIdANDId :one
-SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at FROM short_links
-WHERE user_id = $1 AND id = $2
+SELECT id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at FROM short_links
+WHERE user_id = $1 AND id = $2 AND deleted_at IS NULL
 `
 
 type RetrieveShortLinkByUserIdANDIdParams struct {
@@ -199,17 +1098,35 @@ func (q *Queries) RetrieveShortLinkByUserIdANDId(ctx context.Context, arg Retrie
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const toggleShortLink = `-- name: ToggleShortLink :exec
+const toggleShortLink = `-- name: ToggleShortLink :one
 UPDATE short_links
 SET
   is_active = NOT is_active, -- Toggles the boolean value
   updated_at = NOW()         -- Updates the timestamp to the current time
 WHERE
-  slug = $1 AND user_id = $2
+  slug = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at
 `
 
 type ToggleShortLinkParams struct {
@@ -217,15 +1134,113 @@ type ToggleShortLinkParams struct {
 	UserID uuid.UUID
 }
 
-func (q *Queries) ToggleShortLink(ctx context.Context, arg ToggleShortLinkParams) error {
-	_, err := q.db.ExecContext(ctx, toggleShortLink, arg.Slug, arg.UserID)
-	return err
+func (q *Queries) ToggleShortLink(ctx context.Context, arg ToggleShortLinkParams) (ShortLink, error) {
+	row := q.db.QueryRowContext(ctx, toggleShortLink, arg.Slug, arg.UserID)
+	var i ShortLink
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Slug,
+		&i.OriginalUrl,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
+	)
+	return i, err
 }
 
-const updateShortLinkSlug = `-- name: UpdateShortLinkSlug :exec
+const trashShortLinkBySlugNUserId = `-- name: TrashShortLinkBySlugNUserId :one
+UPDATE short_links
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at
+`
+
+type TrashShortLinkBySlugNUserIdParams struct {
+	Slug   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) TrashShortLinkBySlugNUserId(ctx context.Context, arg TrashShortLinkBySlugNUserIdParams) (ShortLink, error) {
+	row := q.db.QueryRowContext(ctx, trashShortLinkBySlugNUserId, arg.Slug, arg.UserID)
+	var i ShortLink
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Slug,
+		&i.OriginalUrl,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateShortLinkPrivacyMode = `-- name: UpdateShortLinkPrivacyMode :execrows
+UPDATE short_links
+SET privacy_mode = $3, updated_at = NOW()
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NULL
+`
+
+type UpdateShortLinkPrivacyModeParams struct {
+	Slug        string
+	UserID      uuid.UUID
+	PrivacyMode string
+}
+
+func (q *Queries) UpdateShortLinkPrivacyMode(ctx context.Context, arg UpdateShortLinkPrivacyModeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateShortLinkPrivacyMode, arg.Slug, arg.UserID, arg.PrivacyMode)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateShortLinkSlug = `-- name: UpdateShortLinkSlug :one
 UPDATE short_links
 SET slug = $3,updated_at = NOW()
-WHERE slug = $1 AND user_id = $2
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign, is_active, created_at, updated_at, title, folder_id, campaign_id, utm_term, utm_content, extra_params, utm_policy, pass_query, privacy_mode, health_action, health_fallback_url, quarantined_at, quarantine_reason, quarantined_by, blocked_at, blocked_reason, deleted_at
 `
 
 type UpdateShortLinkSlugParams struct {
@@ -234,15 +1249,46 @@ type UpdateShortLinkSlugParams struct {
 	Slug_2 string
 }
 
-func (q *Queries) UpdateShortLinkSlug(ctx context.Context, arg UpdateShortLinkSlugParams) error {
-	_, err := q.db.ExecContext(ctx, updateShortLinkSlug, arg.Slug, arg.UserID, arg.Slug_2)
-	return err
+func (q *Queries) UpdateShortLinkSlug(ctx context.Context, arg UpdateShortLinkSlugParams) (ShortLink, error) {
+	row := q.db.QueryRowContext(ctx, updateShortLinkSlug, arg.Slug, arg.UserID, arg.Slug_2)
+	var i ShortLink
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Slug,
+		&i.OriginalUrl,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.FolderID,
+		&i.CampaignID,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.ExtraParams,
+		&i.UtmPolicy,
+		&i.PassQuery,
+		&i.PrivacyMode,
+		&i.HealthAction,
+		&i.HealthFallbackUrl,
+		&i.QuarantinedAt,
+		&i.QuarantineReason,
+		&i.QuarantinedBy,
+		&i.BlockedAt,
+		&i.BlockedReason,
+		&i.DeletedAt,
+	)
+	return i, err
 }
 
 const updateShortLinkUTM = `-- name: UpdateShortLinkUTM :exec
 UPDATE short_links
-SET utm_source = $2, utm_medium = $3, utm_campaign = $4,updated_at = NOW()
-WHERE slug = $1 AND user_id = $5
+SET utm_source = $2, utm_medium = $3, utm_campaign = $4,updated_at = NOW(),
+utm_term = $6, utm_content = $7, extra_params = $8, utm_policy = $9, pass_query = $10
+WHERE slug = $1 AND user_id = $5 AND deleted_at IS NULL
 `
 
 type UpdateShortLinkUTMParams struct {
@@ -251,6 +1297,11 @@ type UpdateShortLinkUTMParams struct {
 	UtmMedium   string
 	UtmCampaign string
 	UserID      uuid.UUID
+	UtmTerm     string
+	UtmContent  string
+	ExtraParams json.RawMessage
+	UtmPolicy   string
+	PassQuery   bool
 }
 
 func (q *Queries) UpdateShortLinkUTM(ctx context.Context, arg UpdateShortLinkUTMParams) error {
@@ -260,6 +1311,11 @@ func (q *Queries) UpdateShortLinkUTM(ctx context.Context, arg UpdateShortLinkUTM
 		arg.UtmMedium,
 		arg.UtmCampaign,
 		arg.UserID,
+		arg.UtmTerm,
+		arg.UtmContent,
+		arg.ExtraParams,
+		arg.UtmPolicy,
+		arg.PassQuery,
 	)
 	return err
 }
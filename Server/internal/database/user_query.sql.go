@@ -7,10 +7,22 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 )
 
+const cancelUserDeletion = `-- name: CancelUserDeletion :exec
+UPDATE users
+SET deletion_scheduled_at = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, cancelUserDeletion, id)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users(id, name, email, password, created_at)
 VALUES(
@@ -19,7 +31,7 @@ VALUES(
     $2,
     $3,
     NOW()
-) RETURNING id, name, email, password, created_at, updated_at
+) RETURNING id, name, email, password, created_at, updated_at, email_verified, mfa_enabled, mfa_secret, mfa_last_used_step, tokens_valid_after, click_retention_days, deletion_scheduled_at, privacy_mode, role, suspended_at, suspension_reason
 `
 
 type CreateUserParams struct {
@@ -38,12 +50,95 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerified,
+		&i.MfaEnabled,
+		&i.MfaSecret,
+		&i.MfaLastUsedStep,
+		&i.TokensValidAfter,
+		&i.ClickRetentionDays,
+		&i.DeletionScheduledAt,
+		&i.PrivacyMode,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
+const disableUserMfa = `-- name: DisableUserMfa :exec
+UPDATE users
+SET mfa_enabled = FALSE, mfa_secret = '', mfa_last_used_step = 0, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) DisableUserMfa(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, disableUserMfa, id)
+	return err
+}
+
+const enableUserMfa = `-- name: EnableUserMfa :exec
+UPDATE users
+SET mfa_enabled = TRUE, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) EnableUserMfa(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, enableUserMfa, id)
+	return err
+}
+
+const getUserPrivacyMode = `-- name: GetUserPrivacyMode :one
+SELECT privacy_mode FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserPrivacyMode(ctx context.Context, id uuid.UUID) (string, error) {
+	row := q.db.QueryRowContext(ctx, getUserPrivacyMode, id)
+	var privacyMode string
+	err := row.Scan(&privacyMode)
+	return privacyMode, err
+}
+
+const listUsersDueForDeletion = `-- name: ListUsersDueForDeletion :many
+SELECT id FROM users
+WHERE deletion_scheduled_at <= NOW()
+`
+
+func (q *Queries) ListUsersDueForDeletion(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersDueForDeletion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const retrieveUserByEmail = `-- name: RetrieveUserByEmail :one
-SELECT id, name, email, password, created_at, updated_at FROM users
+SELECT id, name, email, password, created_at, updated_at, email_verified, mfa_enabled, mfa_secret, mfa_last_used_step, tokens_valid_after, click_retention_days, deletion_scheduled_at, privacy_mode, role, suspended_at, suspension_reason FROM users
 WHERE email = $1
 `
 
@@ -57,12 +152,23 @@ func (q *Queries) RetrieveUserByEmail(ctx context.Context, email string) (User,
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerified,
+		&i.MfaEnabled,
+		&i.MfaSecret,
+		&i.MfaLastUsedStep,
+		&i.TokensValidAfter,
+		&i.ClickRetentionDays,
+		&i.DeletionScheduledAt,
+		&i.PrivacyMode,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
 	)
 	return i, err
 }
 
 const retrieveUserById = `-- name: RetrieveUserById :one
-SELECT id, name, email, password, created_at, updated_at FROM users
+SELECT id, name, email, password, created_at, updated_at, email_verified, mfa_enabled, mfa_secret, mfa_last_used_step, tokens_valid_after, click_retention_days, deletion_scheduled_at, privacy_mode, role, suspended_at, suspension_reason FROM users
 WHERE id = $1
 `
 
@@ -76,10 +182,75 @@ func (q *Queries) RetrieveUserById(ctx context.Context, id uuid.UUID) (User, err
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerified,
+		&i.MfaEnabled,
+		&i.MfaSecret,
+		&i.MfaLastUsedStep,
+		&i.TokensValidAfter,
+		&i.ClickRetentionDays,
+		&i.DeletionScheduledAt,
+		&i.PrivacyMode,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
 	)
 	return i, err
 }
 
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = NOW() + make_interval(days => $1::int), updated_at = NOW()
+WHERE id = $2
+RETURNING deletion_scheduled_at
+`
+
+type ScheduleUserDeletionParams struct {
+	GraceDays int32
+	ID        uuid.UUID
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, scheduleUserDeletion, arg.GraceDays, arg.ID)
+	var deletionScheduledAt sql.NullTime
+	err := row.Scan(&deletionScheduledAt)
+	return deletionScheduledAt, err
+}
+
+const setUserClickRetention = `-- name: SetUserClickRetention :execrows
+UPDATE users
+SET click_retention_days = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserClickRetentionParams struct {
+	ID                 uuid.UUID
+	ClickRetentionDays sql.NullInt32
+}
+
+func (q *Queries) SetUserClickRetention(ctx context.Context, arg SetUserClickRetentionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setUserClickRetention, arg.ID, arg.ClickRetentionDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setUserMfaSecret = `-- name: SetUserMfaSecret :exec
+UPDATE users
+SET mfa_secret = $2, mfa_enabled = FALSE, mfa_last_used_step = 0, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserMfaSecretParams struct {
+	ID        uuid.UUID
+	MfaSecret string
+}
+
+func (q *Queries) SetUserMfaSecret(ctx context.Context, arg SetUserMfaSecretParams) error {
+	_, err := q.db.ExecContext(ctx, setUserMfaSecret, arg.ID, arg.MfaSecret)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :exec
 UPDATE users
 SET name = $2, email = $3, password = $4, updated_at = NOW()
@@ -105,7 +276,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 
 const updateUserEmail = `-- name: UpdateUserEmail :exec
 UPDATE users
-SET email = $2, updated_at = NOW()
+SET email = $2, email_verified = TRUE, updated_at = NOW()
 WHERE id = $1
 `
 
@@ -119,6 +290,25 @@ func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams
 	return err
 }
 
+const updateUserMfaLastUsedStep = `-- name: UpdateUserMfaLastUsedStep :execrows
+UPDATE users
+SET mfa_last_used_step = $2
+WHERE id = $1 AND mfa_last_used_step < $2
+`
+
+type UpdateUserMfaLastUsedStepParams struct {
+	ID              uuid.UUID
+	MfaLastUsedStep int64
+}
+
+func (q *Queries) UpdateUserMfaLastUsedStep(ctx context.Context, arg UpdateUserMfaLastUsedStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserMfaLastUsedStep, arg.ID, arg.MfaLastUsedStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateUserName = `-- name: UpdateUserName :exec
 UPDATE users
 SET name = $2, updated_at = NOW()
@@ -137,7 +327,7 @@ func (q *Queries) UpdateUserName(ctx context.Context, arg UpdateUserNameParams)
 
 const updateUserPassword = `-- name: UpdateUserPassword :exec
 UPDATE users
-SET password = $2, updated_at = NOW()
+SET password = $2, tokens_valid_after = FLOOR(EXTRACT(EPOCH FROM NOW()))::BIGINT, updated_at = NOW()
 WHERE id = $1
 `
 
@@ -150,3 +340,30 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.ID, arg.Password)
 	return err
 }
+
+const updateUserPrivacyMode = `-- name: UpdateUserPrivacyMode :exec
+UPDATE users
+SET privacy_mode = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateUserPrivacyModeParams struct {
+	ID          uuid.UUID
+	PrivacyMode string
+}
+
+func (q *Queries) UpdateUserPrivacyMode(ctx context.Context, arg UpdateUserPrivacyModeParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPrivacyMode, arg.ID, arg.PrivacyMode)
+	return err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :exec
+UPDATE users
+SET email_verified = TRUE, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, verifyUserEmail, id)
+	return err
+}
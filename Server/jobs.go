@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Runs the job now and on every tick; failures are logged and retried on the next tick
+func runEvery(interval time.Duration, name string, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := job(context.Background()); err != nil {
+			log.Printf("Failed to %s: %v", name, err)
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLinkPageSize = 50
+	maxLinkPageSize     = 200
+)
+
+var linkSortOrders = map[string]bool{
+	"created_desc": true,
+	"created_asc":  true,
+	"clicks_desc":  true,
+	"clicks_asc":   true,
+}
+
+// Position of the last row of a page in the requested sort order
+type linkCursor struct {
+	CreatedAt time.Time `json:"t"`
+	Clicks    int64     `json:"c"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func encodeLinkCursor(row database.ListShortLinksWithStatsRow) string {
+	raw, _ := json.Marshal(linkCursor{CreatedAt: row.CreatedAt, Clicks: row.TotalClicks, ID: row.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeLinkCursor(cursor string) (linkCursor, error) {
+	var lc linkCursor
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return lc, err
+	}
+	if err := json.Unmarshal(raw, &lc); err != nil {
+		return lc, err
+	}
+	if lc.ID == uuid.Nil {
+		return lc, errors.New("cursor is missing an id")
+	}
+	return lc, nil
+}
+
+// Accepts either a full RFC 3339 timestamp or a plain date
+func parseListDate(value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return sql.NullTime{}, err
+		}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
+
+// A folder filter includes its subfolders
+func linkListParams(c *gin.Context, userID uuid.UUID) (database.ListShortLinksWithStatsParams, error) {
+	params := database.ListShortLinksWithStatsParams{
+		UserID:    userID,
+		Sort:      c.DefaultQuery("sort", "created_desc"),
+		PageLimit: defaultLinkPageSize,
+	}
+	if !linkSortOrders[params.Sort] {
+		return params, errors.New("sort must be one of created_desc, created_asc, clicks_desc, clicks_asc")
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		params.Search = sql.NullString{String: q, Valid: true}
+	}
+	if active := c.Query("active"); active != "" {
+		isActive, err := strconv.ParseBool(active)
+		if err != nil {
+			return params, errors.New("active must be true or false")
+		}
+		params.IsActive = sql.NullBool{Bool: isActive, Valid: true}
+	}
+	if campaign := c.Query("campaign"); campaign != "" {
+		params.Campaign = sql.NullString{String: campaign, Valid: true}
+	}
+	if campaign := c.Query("campaign_id"); campaign != "" {
+		campaignID, err := uuid.Parse(campaign)
+		if err != nil {
+			return params, errors.New("campaign_id must be a campaign id")
+		}
+		params.CampaignID = uuid.NullUUID{UUID: campaignID, Valid: true}
+	}
+	if tag := c.Query("tag"); tag != "" {
+		tagID, err := uuid.Parse(tag)
+		if err != nil {
+			return params, errors.New("tag must be a tag id")
+		}
+		params.TagID = uuid.NullUUID{UUID: tagID, Valid: true}
+	}
+	if folder := c.Query("folder"); folder != "" {
+		folderID, err := uuid.Parse(folder)
+		if err != nil {
+			return params, errors.New("folder must be a folder id")
+		}
+		params.FolderID = uuid.NullUUID{UUID: folderID, Valid: true}
+	}
+	if health := c.Query("health"); health != "" {
+		if !healthStatuses[health] {
+			return params, errors.New("health must be one of unknown, healthy, failing, broken")
+		}
+		params.Health = sql.NullString{String: health, Valid: true}
+	}
+	var err error
+	if params.CreatedAfter, err = parseListDate(c.Query("created_after")); err != nil {
+		return params, errors.New("created_after must be a date or RFC 3339 timestamp")
+	}
+	if params.CreatedBefore, err = parseListDate(c.Query("created_before")); err != nil {
+		return params, errors.New("created_before must be a date or RFC 3339 timestamp")
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxLinkPageSize {
+			return params, errors.New("limit must be between 1 and 200")
+		}
+		params.PageLimit = int32(n)
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		lc, err := decodeLinkCursor(cursor)
+		if err != nil {
+			return params, errors.New("Invalid cursor")
+		}
+		params.CursorID = uuid.NullUUID{UUID: lc.ID, Valid: true}
+		params.CursorTime = sql.NullTime{Time: lc.CreatedAt, Valid: true}
+		params.CursorClicks = sql.NullInt64{Int64: lc.Clicks, Valid: true}
+	}
+	return params, nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	clickChannel = "link_clicks"
+	// Instances announce which links they have live subscribers for
+	watchChannel = "link_clicks_watch"
+	// A subscriber further behind misses clicks instead of slowing redirects
+	liveBufferSize    = 64
+	liveKeepAlive     = 30 * time.Second
+	liveWatchTTL      = 2 * liveKeepAlive
+	listenMinInterval = 10 * time.Second
+	listenMaxInterval = time.Minute
+
+	liveStreamPurpose     = "live_stream"
+	liveStreamTokenExpiry = 15 * time.Minute
+)
+
+// A click passed between instances; origin lets the sender skip its own
+type clickNotification struct {
+	Origin      uuid.UUID  `json:"origin"`
+	ShortLinkID uuid.UUID  `json:"short_link_id"`
+	Event       ClickEvent `json:"event"`
+}
+
+// Sent when an instance gains a subscriber for a link and on every keep-alive
+type watchNotification struct {
+	Origin      uuid.UUID `json:"origin"`
+	ShortLinkID uuid.UUID `json:"short_link_id"`
+}
+
+// In-process pub/sub for live clicks, keyed by short link
+type clickHub struct {
+	id          uuid.UUID
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan ClickEvent]struct{}
+	// Links other instances watch, until their last announcement expires
+	remote map[uuid.UUID]time.Time
+}
+
+func newClickHub() *clickHub {
+	return &clickHub{
+		id:          uuid.New(),
+		subscribers: map[uuid.UUID]map[chan ClickEvent]struct{}{},
+		remote:      map[uuid.UUID]time.Time{},
+	}
+}
+
+func (h *clickHub) subscribe(linkID uuid.UUID) (chan ClickEvent, func()) {
+	ch := make(chan ClickEvent, liveBufferSize)
+	h.mu.Lock()
+	if h.subscribers[linkID] == nil {
+		h.subscribers[linkID] = map[chan ClickEvent]struct{}{}
+	}
+	h.subscribers[linkID][ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers[linkID], ch)
+		if len(h.subscribers[linkID]) == 0 {
+			delete(h.subscribers, linkID)
+		}
+		h.mu.Unlock()
+	}
+}
+
+func (h *clickHub) publish(event ClickEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers[event.ShortLinkID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (h *clickHub) watchedElsewhere(linkID uuid.UUID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	until, ok := h.remote[linkID]
+	if ok && time.Now().After(until) {
+		delete(h.remote, linkID)
+		return false
+	}
+	return ok
+}
+
+// Retries setting up the listener with a growing pause
+func (h *clickHub) listen(dbLink string) {
+	wait := listenMinInterval
+	for {
+		err := h.listenOnce(dbLink, func() { wait = listenMinInterval })
+		log.Printf("Failed to listen for clicks, retrying in %s: %v", wait, err)
+		time.Sleep(wait)
+		wait = min(wait*2, listenMaxInterval)
+	}
+}
+
+// started is called once both channels are being listened to
+func (h *clickHub) listenOnce(dbLink string, started func()) error {
+	listener := pq.NewListener(dbLink, listenMinInterval, listenMaxInterval, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Failed to listen for clicks: %v", err)
+		}
+	})
+	defer listener.Close()
+	for _, channel := range []string{clickChannel, watchChannel} {
+		if err := listener.Listen(channel); err != nil {
+			return err
+		}
+	}
+	started()
+	for n := range listener.Notify {
+		if n == nil {
+			continue
+		}
+		switch n.Channel {
+		case clickChannel:
+			var payload clickNotification
+			if err := json.Unmarshal([]byte(n.Extra), &payload); err != nil {
+				log.Printf("Failed to decode click notification: %v", err)
+				continue
+			}
+			if payload.Origin == h.id {
+				continue
+			}
+			payload.Event.ShortLinkID = payload.ShortLinkID
+			h.publish(payload.Event)
+		case watchChannel:
+			var payload watchNotification
+			if err := json.Unmarshal([]byte(n.Extra), &payload); err != nil {
+				log.Printf("Failed to decode watch notification: %v", err)
+				continue
+			}
+			if payload.Origin == h.id {
+				continue
+			}
+			h.mu.Lock()
+			h.remote[payload.ShortLinkID] = time.Now().Add(liveWatchTTL)
+			h.mu.Unlock()
+		}
+	}
+	return errors.New("listener closed")
+}
+
+// Delivers locally, and through NOTIFY when another instance watches the link
+func (cfg *apiCfg) broadcastClick(ctx context.Context, event ClickEvent) {
+	cfg.clickHub.publish(event)
+	if !cfg.clickHub.watchedElsewhere(event.ShortLinkID) {
+		return
+	}
+	payload, err := json.Marshal(clickNotification{
+		Origin:      cfg.clickHub.id,
+		ShortLinkID: event.ShortLinkID,
+		Event:       event,
+	})
+	if err != nil {
+		log.Printf("Failed to encode click notification: %v", err)
+		return
+	}
+	if err := cfg.db.NotifyClick(ctx, string(payload)); err != nil {
+		log.Printf("Failed to notify click: %v", err)
+	}
+}
+
+// Tells the other instances this one has a subscriber for the link
+func (cfg *apiCfg) announceWatch(ctx context.Context, linkID uuid.UUID) {
+	payload, err := json.Marshal(watchNotification{Origin: cfg.clickHub.id, ShortLinkID: linkID})
+	if err != nil {
+		log.Printf("Failed to encode watch notification: %v", err)
+		return
+	}
+	if err := cfg.db.NotifyLiveWatch(ctx, string(payload)); err != nil {
+		log.Printf("Failed to announce live subscriber: %v", err)
+	}
+}
+
+// A short-lived token for one link, since EventSource can't send headers
+func createLiveStreamToken(userID uuid.UUID, slug string, tokenSecret string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(liveStreamTokenExpiry)
+	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":     userID,
+		"iss":     "urlShortener",
+		"exp":     expiresAt.Unix(),
+		"iat":     time.Now().Unix(),
+		"purpose": liveStreamPurpose,
+		"slug":    slug,
+	})
+	token, err := claims.SignedString([]byte(tokenSecret))
+	return token, expiresAt, err
+}
+
+// Issues a token for opening the link's live stream from a browser
+func (cfg *apiCfg) CreateLiveStreamToken(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	slug := c.Param("slug")
+	if slug == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	_, err := cfg.db.RetrieveShortLinkBySlugNUserId(c, database.RetrieveShortLinkBySlugNUserIdParams{
+		UserID: user.ID,
+		Slug:   slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	token, expiresAt, err := createLiveStreamToken(user.ID, slug, cfg.jwtSecret)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, LiveStreamTokenRes{Token: token, ExpiresAt: expiresAt})
+}
+
+// Streams clicks as server-sent events until the stream token expires
+func (cfg *apiCfg) StreamLinkClicks(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	slug := c.Param("slug")
+	if slug == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	link, err := cfg.db.RetrieveShortLinkBySlugNUserId(c, database.RetrieveShortLinkBySlugNUserIdParams{
+		UserID: user.ID,
+		Slug:   slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	events, unsubscribe := cfg.clickHub.subscribe(link.ID)
+	defer unsubscribe()
+	cfg.announceWatch(c, link.ID)
+	keepAlive := time.NewTicker(liveKeepAlive)
+	defer keepAlive.Stop()
+	expiry := time.NewTimer(time.Until(c.GetTime("tokenExpiresAt")))
+	defer expiry.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-events:
+			c.SSEvent("click", event)
+			return true
+		case <-keepAlive.C:
+			cfg.announceWatch(c, link.ID)
+			c.SSEvent("ping", time.Now().UTC().Format(time.RFC3339))
+			return true
+		case <-expiry.C:
+			c.SSEvent("expired", time.Now().UTC().Format(time.RFC3339))
+			return false
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"net/http"
 	"os"
 
 	"github.com/HarmanPreet-Singh-XYT/internal/database"
@@ -13,10 +15,24 @@ import (
 
 type apiCfg struct {
 	db               *database.Queries
+	conn             *sql.DB
 	port             string
 	frontendOrigin   string
 	jwtSecret        string
 	jwtRefreshSecret string
+	mailer           Mailer
+	oidcProviders    map[string]*oidcProvider
+	breachList       BreachChecker
+	retention        retentionPolicy
+	geoDB            *GeoDB
+	referrers        *referrerTable
+	clickHub         *clickHub
+	alertNotifiers   map[string]AlertNotifier
+	clock            Clock
+	healthClient     *http.Client
+	threats          ThreatChecker
+	ipHashSecret     string
+	trashDays        int
 }
 
 func main() {
@@ -41,17 +57,63 @@ func main() {
 	dbQ := database.New(dbConn)
 	cfg := apiCfg{
 		db:               dbQ,
+		conn:             dbConn,
 		port:             port,
 		frontendOrigin:   frontendOrigin,
 		jwtSecret:        jwtS,
 		jwtRefreshSecret: jwtRS,
+		mailer:           newMailerFromEnv(),
+		oidcProviders:    newOIDCProvidersFromEnv(),
+		breachList:       &LocalBreachList{dir: os.Getenv("BREACHED_PASSWORDS_DIR")},
+		retention:        newRetentionPolicyFromEnv(),
+		geoDB:            newGeoDBFromEnv(),
+		referrers:        &referrerTable{},
+		clickHub:         newClickHub(),
+		clock:            systemClock{},
+		healthClient:     newHealthClient(),
+		ipHashSecret:     os.Getenv("IP_HASH_SECRET"),
+		trashDays:        trashDaysFromEnv(),
 	}
+	if cfg.ipHashSecret == "" {
+		cfg.ipHashSecret = jwtS
+	}
+	cfg.alertNotifiers = newAlertNotifiers(&cfg)
+	threatList := newThreatListFromEnv()
+	if err := threatList.Reload(true); err != nil {
+		log.Printf("Failed to load threat lists: %v", err)
+	}
+	cfg.threats = threatList
+	if len(os.Args) > 1 {
+		if err := cfg.runCommand(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	go cfg.clickHub.listen(dbLink)
+	go runEvery(referrerSourcesInterval, "load referrer sources", cfg.loadReferrerSources)
+	go runEvery(webhookDeliveryInterval, "deliver webhooks", cfg.deliverWebhooks)
+	go runEvery(alertCheckInterval, "evaluate alerts", cfg.evaluateAlerts)
+	go reloadOnHangup(threatList)
+	go runEvery(threatReloadInterval, "reload threat lists", func(ctx context.Context) error {
+		return threatList.Reload(false)
+	})
+	go runEvery(threatScanInterval, "rescan links", cfg.rescanLinks)
+	go runEvery(linkHealthInterval, "check link health", cfg.checkLinkHealth)
+	go runEvery(scheduleExpiryInterval, "notify expired links", cfg.notifyExpiredLinks)
+	go runEvery(clickRollupInterval, "roll up clicks", cfg.rollUpClicks)
+	go runEvery(clickRetentionInterval, "prune clicks", func(ctx context.Context) error {
+		_, err := cfg.pruneClicks(ctx)
+		return err
+	})
+	go runEvery(accountPurgeInterval, "purge deleted accounts", cfg.purgeDeletedAccounts)
+	go runEvery(trashPurgeInterval, "purge trashed links", cfg.purgeTrash)
 
 	router := gin.Default()
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{cfg.frontendOrigin}
 	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
-	config.AllowMethods = []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"}
+	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	config.AllowCredentials = true
 	router.Use(cors.New(config))
 	{
@@ -64,26 +126,112 @@ func main() {
 		auth.POST("/register", cfg.registerUser)
 		auth.POST("/login", cfg.loginUser)
 		auth.POST("/token/renew", cfg.renewToken)
+		auth.POST("/email/verify", cfg.verifyEmail)
+		auth.POST("/email/confirm", cfg.confirmEmailChange)
+		auth.POST("/password/forgot", cfg.forgotPassword)
+		auth.POST("/password/reset", cfg.resetPassword)
+		auth.POST("/mfa/verify", cfg.verifyMfaLogin)
+		auth.GET("/oidc/providers", cfg.listOIDCProviders)
+		auth.POST("/oidc/:provider/start", cfg.startOIDCLogin)
+		auth.POST("/oidc/:provider/callback", cfg.finishOIDCLogin)
 	}
 	{
 		userAccess := router.Group("/user")
 		userAccess.Use(cfg.checkAuth())
 		userAccess.POST("/profile", cfg.profileInfo)
+		userAccess.GET("/export", cfg.ExportAccount)
+		userAccess.DELETE("", cfg.DeleteAccount)
+		userAccess.POST("/deletion/cancel", cfg.CancelAccountDeletion)
+		userAccess.GET("/profile/signins", cfg.GetSignIns)
+		userAccess.GET("/audit", cfg.GetAuditLog)
+		userAccess.POST("/email/verify/resend", cfg.ResendVerification)
+		userAccess.POST("/mfa/enroll", cfg.EnrollMfa)
+		userAccess.POST("/mfa/activate", cfg.ActivateMfa)
+		userAccess.POST("/mfa/disable", cfg.DisableMfa)
 		userAccess.PATCH("/update", cfg.ProfileUpdate)
+		userAccess.PATCH("/password", cfg.ChangePassword)
+		userAccess.PATCH("/email", cfg.ChangeEmail)
+		userAccess.PATCH("/privacy", cfg.UpdatePrivacyMode)
+		userAccess.GET("/analytics", cfg.GetAccountAnalytics)
 		userAccess.GET("/links", cfg.GetLinks)
-		userAccess.POST("/shorten", cfg.shortenLink)
+		userAccess.POST("/shorten", requireVerifiedEmail(), cfg.shortenLink)
 		userAccess.GET("/links/:slug", cfg.GetLink)
 		userAccess.DELETE("/links/:slug", cfg.DeleteLink)
+		userAccess.GET("/trash", cfg.GetTrash)
+		userAccess.POST("/trash/:slug/restore", cfg.RestoreLink)
+		userAccess.DELETE("/trash/:slug", cfg.PurgeLink)
 		userAccess.GET("/links/:slug/analytics", cfg.GetAnalytics)
+		userAccess.POST("/links/:slug/live/token", cfg.CreateLiveStreamToken)
+		userAccess.GET("/links/:slug/schedule", cfg.GetLinkSchedule)
+		userAccess.PUT("/links/:slug/schedule", cfg.PutLinkSchedule)
+		userAccess.DELETE("/links/:slug/schedule", cfg.DeleteLinkSchedule)
+		userAccess.GET("/links/:slug/health", cfg.GetLinkHealth)
+		userAccess.POST("/links/:slug/health/check", cfg.CheckLinkHealthNow)
+		userAccess.POST("/links/tags", cfg.UpdateLinkTags)
+		userAccess.POST("/links/folder", cfg.MoveLinksToFolder)
+		userAccess.POST("/links/campaign", cfg.AttachLinksToCampaign)
+		userAccess.GET("/tags", cfg.GetTags)
+		userAccess.POST("/tags", cfg.CreateTag)
+		userAccess.PATCH("/tags/:id", cfg.UpdateTag)
+		userAccess.DELETE("/tags/:id", cfg.DeleteTag)
+		userAccess.GET("/tags/:id/analytics", cfg.GetTagAnalytics)
+		userAccess.GET("/folders", cfg.GetFolders)
+		userAccess.POST("/folders", cfg.CreateFolder)
+		userAccess.PATCH("/folders/:id", cfg.UpdateFolder)
+		userAccess.DELETE("/folders/:id", cfg.DeleteFolder)
+		userAccess.GET("/folders/:id/analytics", cfg.GetFolderAnalytics)
+		userAccess.GET("/campaigns", cfg.GetCampaigns)
+		userAccess.POST("/campaigns", cfg.CreateCampaign)
+		userAccess.GET("/campaigns/:id", cfg.GetCampaign)
+		userAccess.PATCH("/campaigns/:id", cfg.UpdateCampaign)
+		userAccess.DELETE("/campaigns/:id", cfg.DeleteCampaign)
+		userAccess.GET("/campaigns/:id/analytics", cfg.GetCampaignAnalytics)
+		userAccess.GET("/webhooks", cfg.GetWebhooks)
+		userAccess.POST("/webhooks", requireVerifiedEmail(), cfg.CreateWebhook)
+		userAccess.PATCH("/webhooks/:id", requireVerifiedEmail(), cfg.UpdateWebhook)
+		userAccess.DELETE("/webhooks/:id", cfg.DeleteWebhook)
+		userAccess.GET("/webhooks/:id/deliveries", cfg.GetWebhookDeliveries)
+		userAccess.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", requireVerifiedEmail(), cfg.RedeliverWebhook)
+		userAccess.GET("/fallbacks", cfg.GetFallbackPages)
+		userAccess.PUT("/fallbacks/:kind", cfg.PutFallbackPage)
+		userAccess.DELETE("/fallbacks/:kind", cfg.DeleteFallbackPage)
+		userAccess.GET("/alerts", cfg.GetAlertRules)
+		userAccess.POST("/alerts", requireVerifiedEmail(), cfg.CreateAlertRule)
+		userAccess.PATCH("/alerts/:id/mute", cfg.MuteAlertRule)
+		userAccess.DELETE("/alerts/:id", cfg.DeleteAlertRule)
 		userAccess.PATCH("/toggle/:slug", cfg.ToggleLink)
 		userAccess.PATCH("/link/utm/:slug", cfg.UpdateUTM)
+		userAccess.PATCH("/link/privacy/:slug", cfg.UpdateLinkPrivacy)
+		userAccess.PATCH("/link/health/:slug", cfg.UpdateLinkHealthAction)
 		userAccess.PATCH("/link/:slug", cfg.UpdateSlug)
 		userAccess.POST("/logout", cfg.LogoutUser)
 	}
+	{
+		// Outside the /user group: browsers authenticate it with a stream token instead
+		router.GET("/user/links/:slug/live", cfg.checkLiveStreamAuth(), cfg.StreamLinkClicks)
+	}
 	{
 		api := router.Group("/api")
 		api.POST("/redirect/:slug", cfg.RedirectLink)
+		api.POST("/report/:slug", cfg.ReportLink)
+	}
+	{
+		admin := router.Group("/admin")
+		admin.Use(cfg.checkAuth(), requireAdmin())
+		admin.GET("/stats", cfg.GetSystemStats)
+		admin.GET("/users", cfg.AdminGetUsers)
+		admin.POST("/users/:id/suspend", cfg.AdminSuspendUser)
+		admin.POST("/users/:id/unsuspend", cfg.AdminUnsuspendUser)
+		admin.GET("/links", cfg.AdminGetLinks)
+		admin.POST("/links/:slug/block", cfg.AdminBlockLink)
+		admin.POST("/links/:slug/unblock", cfg.AdminUnblockLink)
+		admin.POST("/links/:slug/quarantine", cfg.AdminQuarantineLink)
+		admin.POST("/links/:slug/release", cfg.AdminReleaseLink)
+		admin.GET("/reports", cfg.AdminGetReports)
+		admin.PATCH("/reports/:id", cfg.AdminResolveReport)
+		admin.GET("/audit", cfg.AdminGetAuditLog)
 	}
+	router.GET("/r/:slug", cfg.FollowLink)
 
 	router.Run(":" + cfg.port)
 }
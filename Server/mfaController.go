@@ -0,0 +1,215 @@
+package main
+
+import (
+	cryptorand "crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// Replaces all of the user's recovery codes and returns the new ones in plain text, once
+func (cfg *apiCfg) generateRecoveryCodes(c *gin.Context, userID uuid.UUID) ([]string, error) {
+	if err := cfg.db.DeleteRecoveryCodesByUserId(c, userID); err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, 10)
+		for i := range b {
+			n, err := cryptorand.Int(cryptorand.Reader, big.NewInt(int64(len(charset))))
+			if err != nil {
+				return nil, err
+			}
+			b[i] = charset[n.Int64()]
+		}
+		code := string(b[:5]) + "-" + string(b[5:])
+		err := cfg.db.CreateRecoveryCode(c, database.CreateRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hashToken(normalizeRecoveryCode(code)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// Accepts a TOTP code (rejecting reuse of an already used time step) or an unused recovery code
+func (cfg *apiCfg) checkSecondFactor(c *gin.Context, user database.User, code string, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		affected, err := cfg.db.UseRecoveryCode(c, database.UseRecoveryCodeParams{
+			UserID:   user.ID,
+			CodeHash: hashToken(normalizeRecoveryCode(recoveryCode)),
+		})
+		return affected == 1, err
+	}
+	step, ok := validateTOTP(user.MfaSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	affected, err := cfg.db.UpdateUserMfaLastUsedStep(c, database.UpdateUserMfaLastUsedStepParams{
+		ID:              user.ID,
+		MfaLastUsedStep: step,
+	})
+	return affected == 1, err
+}
+
+func (cfg *apiCfg) EnrollMfa(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	if user.MfaEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err := cfg.db.SetUserMfaSecret(c, database.SetUserMfaSecretParams{ID: user.ID, MfaSecret: secret}); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	uri := totpURI(user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, MfaEnrollRes{
+		Secret:     secret,
+		OtpauthURI: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+func (cfg *apiCfg) ActivateMfa(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data MfaCodeReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if user.MfaEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.MfaSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start enrollment first"})
+		return
+	}
+	ok, err := cfg.checkSecondFactor(c, user, data.Code, "")
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+	if err := cfg.db.EnableUserMfa(c, user.ID); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	codes, err := cfg.generateRecoveryCodes(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesRes{RecoveryCodes: codes})
+}
+
+func (cfg *apiCfg) DisableMfa(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data PasswordConfirmReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(data.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password does not match"})
+		return
+	}
+	if err := cfg.db.DisableUserMfa(c, user.ID); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err := cfg.db.DeleteRecoveryCodesByUserId(c, user.ID); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) verifyMfaLogin(c *gin.Context) {
+	var data MfaLoginReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	userID, err := parseMfaChallengeToken(data.ChallengeToken, cfg.jwtSecret)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+	user, err := cfg.db.RetrieveUserById(c, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	wait, err := cfg.loginRetryAfter(c, user.Email)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if wait > 0 {
+		abortLoginRetryAfter(c, wait)
+		return
+	}
+	ok := false
+	if user.MfaEnabled {
+		ok, err = cfg.checkSecondFactor(c, user, data.Code, data.RecoveryCode)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if !ok {
+		cfg.recordLoginAttempt(c, user.ID, user.Email, false)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+	cfg.recordLoginAttempt(c, user.ID, user.Email, true)
+	session, err := cfg.startSession(c, user)
+	if errors.Is(err, errAccountSuspended) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -49,6 +50,12 @@ func (cfg *apiCfg) checkAuth() gin.HandlerFunc {
 			return
 		}
 
+		if _, ok := claims["purpose"]; ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token type"})
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
 		exp, ok := claims["exp"].(float64)
 		if !ok || float64(time.Now().Unix()) > exp {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
@@ -70,17 +77,106 @@ func (cfg *apiCfg) checkAuth() gin.HandlerFunc {
 			return
 		}
 
-		user, err := cfg.db.RetrieveUserById(c, id)
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-			} else {
-				c.AbortWithError(http.StatusInternalServerError, err)
-			}
+		iat, _ := claims["iat"].(float64)
+		user, ok := cfg.loadTokenUser(c, id, iat)
+		if !ok {
 			return
 		}
 
 		c.Set("currentUser", user)
+		c.Set("tokenExpiresAt", time.Unix(int64(exp), 0))
+		c.Next()
+	}
+}
+
+// On failure the response is already written and the request aborted
+func (cfg *apiCfg) loadTokenUser(c *gin.Context, id uuid.UUID, iat float64) (database.User, bool) {
+	user, err := cfg.db.RetrieveUserById(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The account was deleted after the token was issued
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		} else {
+			c.AbortWithError(http.StatusInternalServerError, err)
+		}
+		return database.User{}, false
+	}
+
+	if user.SuspendedAt.Valid {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errAccountSuspended.Error()})
+		return database.User{}, false
+	}
+
+	// Tokens issued before a password change were revoked by it
+	if int64(iat) < user.TokensValidAfter {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return database.User{}, false
+	}
+	return user, true
+}
+
+// Like checkAuth, but also accepts a stream token for the same link in ?token
+func (cfg *apiCfg) checkLiveStreamAuth() gin.HandlerFunc {
+	headerAuth := cfg.checkAuth()
+	return func(c *gin.Context) {
+		tokenString := c.Query("token")
+		if tokenString == "" {
+			headerAuth(c)
+			return
+		}
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return []byte(cfg.jwtSecret), nil
+		}, jwt.WithExpirationRequired())
+		if err != nil || !token.Valid {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok || claims["purpose"] != liveStreamPurpose || claims["slug"] != c.Param("slug") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token type"})
+			return
+		}
+		sub, _ := claims["sub"].(string)
+		id, err := uuid.Parse(sub)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		exp, _ := claims["exp"].(float64)
+		iat, _ := claims["iat"].(float64)
+		user, ok := cfg.loadTokenUser(c, id, iat)
+		if !ok {
+			return
+		}
+		c.Set("currentUser", user)
+		c.Set("tokenExpiresAt", time.Unix(int64(exp), 0))
+		c.Next()
+	}
+}
+
+// Lets only administrators through; runs after checkAuth
+func requireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := sortMiddlewareAuth(c)
+		if user.Role != roleAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Keeps unverified accounts away from features that reach other people; runs after checkAuth
+func requireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := sortMiddlewareAuth(c)
+		if !user.EmailVerified {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Verify your email address to use this feature"})
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Clicks are stored with everything the visitor sent
+	privacyModeOff = "off"
+	// IP cut to its /24 or /48 network; only device type and platform kept
+	privacyModeTruncate = "truncate"
+	// Like truncate, with the IP replaced by a daily keyed hash
+	privacyModeHash = "hash"
+)
+
+var errInvalidPrivacyMode = errors.New("privacy_mode must be off, truncate or hash")
+
+// Links may leave the mode empty to follow the account setting
+func validatePrivacyMode(mode string, allowInherit bool) error {
+	switch mode {
+	case privacyModeOff, privacyModeTruncate, privacyModeHash:
+		return nil
+	case "":
+		if allowInherit {
+			return nil
+		}
+	}
+	return errInvalidPrivacyMode
+}
+
+// The link's own mode wins; links without one use the owner's account setting
+func (cfg *apiCfg) effectivePrivacyMode(c *gin.Context, link database.ShortLink) (string, error) {
+	if link.PrivacyMode != "" {
+		return link.PrivacyMode, nil
+	}
+	return cfg.db.GetUserPrivacyMode(c, link.UserID)
+}
+
+// Visitors that send Do-Not-Track or Global Privacy Control
+func trackingOptOut(c *gin.Context) bool {
+	return c.GetHeader("DNT") == "1" || c.GetHeader("Sec-GPC") == "1"
+}
+
+func truncateIP(ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+	if v4 := addr.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return addr.Mask(net.CIDRMask(48, 128)).String()
+}
+
+func (cfg *apiCfg) hashIP(ip string) string {
+	mac := hmac.New(sha256.New, []byte(cfg.ipHashSecret))
+	mac.Write([]byte(time.Now().UTC().Format("2006-01-02")))
+	mac.Write([]byte{0})
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+func (cfg *apiCfg) anonymizeIP(ip, mode string) string {
+	switch mode {
+	case privacyModeTruncate:
+		return truncateIP(ip)
+	case privacyModeHash:
+		return cfg.hashIP(ip)
+	}
+	return ip
+}
+
+func (cfg *apiCfg) UpdatePrivacyMode(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data PrivacyModeReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if err := validatePrivacyMode(data.PrivacyMode, false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		if err := q.UpdateUserPrivacyMode(c, database.UpdateUserPrivacyModeParams{
+			ID:          user.ID,
+			PrivacyMode: data.PrivacyMode,
+		}); err != nil {
+			return auditEntry{}, err
+		}
+		updated := user
+		updated.PrivacyMode = data.PrivacyMode
+		return auditEntry{
+			Action:     auditUserUpdatePrivacy,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID.String(),
+			AccountID:  user.ID,
+			Before:     userAuditState(user),
+			After:      userAuditState(updated),
+		}, nil
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// An empty privacy_mode makes the link follow the account setting again
+func (cfg *apiCfg) UpdateLinkPrivacy(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	slug := c.Param("slug")
+	if slug == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	var data PrivacyModeReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if err := validatePrivacyMode(data.PrivacyMode, true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var updated database.ShortLink
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		link, err := q.RetrieveShortLinkBySlugNUserId(c, database.RetrieveShortLinkBySlugNUserIdParams{
+			UserID: user.ID,
+			Slug:   slug,
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		rows, err := q.UpdateShortLinkPrivacyMode(c, database.UpdateShortLinkPrivacyModeParams{
+			Slug:        slug,
+			UserID:      user.ID,
+			PrivacyMode: data.PrivacyMode,
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		if rows == 0 {
+			return auditEntry{}, sql.ErrNoRows
+		}
+		updated = link
+		updated.PrivacyMode = data.PrivacyMode
+		return auditEntry{
+			Action:     auditLinkUpdatePrivacy,
+			TargetType: auditTargetLink,
+			TargetID:   link.ID.String(),
+			AccountID:  user.ID,
+			Before:     linkAuditState(link),
+			After:      linkAuditState(updated),
+		}, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	cfg.emitLinkEvent(c, webhookLinkUpdated, updated)
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	channelDirect   = "direct"
+	channelInternal = "internal"
+	channelSearch   = "search"
+	channelSocial   = "social"
+	channelEmail    = "email"
+	channelPaid     = "paid"
+	// Any other site linking to the short link
+	channelReferral = "referral"
+
+	referrerSourcesInterval   = 15 * time.Minute
+	referrerBackfillBatchSize = 1000
+)
+
+// utm_medium values that mark a click as paid or email traffic regardless of the referrer
+var (
+	paidMediums  = map[string]bool{"cpc": true, "ppc": true, "cpm": true, "cpv": true, "paid": true, "paidsearch": true, "paid_search": true, "paidsocial": true, "paid_social": true, "display": true, "banner": true}
+	emailMediums = map[string]bool{"email": true, "e-mail": true, "newsletter": true}
+)
+
+// The referrer's host without a leading www. or m.
+func referrerDomain(referrer string) string {
+	u, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, prefix := range []string{"www.", "m."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	return host
+}
+
+type referrerSource struct {
+	name    string
+	channel string
+}
+
+// In-memory copy of referrer_sources, reloaded periodically
+type referrerTable struct {
+	mu      sync.RWMutex
+	sources map[string]referrerSource
+}
+
+// Tries the domain, then each parent domain
+func (t *referrerTable) lookup(domain string) (referrerSource, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for domain != "" {
+		if source, ok := t.sources[domain]; ok {
+			return source, true
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	return referrerSource{}, false
+}
+
+func (cfg *apiCfg) loadReferrerSources(ctx context.Context) error {
+	rows, err := cfg.db.ListReferrerSources(ctx)
+	if err != nil {
+		return err
+	}
+	sources := make(map[string]referrerSource, len(rows))
+	for _, row := range rows {
+		sources[row.Domain] = referrerSource{name: row.Name, channel: row.Channel}
+	}
+	cfg.referrers.mu.Lock()
+	cfg.referrers.sources = sources
+	cfg.referrers.mu.Unlock()
+	return nil
+}
+
+// Paid UTM mediums win over the referrer; no referrer means direct unless tagged as email
+func (cfg *apiCfg) classifyReferrer(referrer, utmMedium string) (domain, source, channel string) {
+	domain = referrerDomain(referrer)
+	source = domain
+	known, ok := cfg.referrers.lookup(domain)
+	if ok {
+		source = known.name
+	}
+	medium := strings.ToLower(strings.TrimSpace(utmMedium))
+	switch {
+	case paidMediums[medium]:
+		channel = channelPaid
+	case domain == "":
+		if emailMediums[medium] {
+			channel = channelEmail
+		} else {
+			channel = channelDirect
+		}
+	case domain == referrerDomain(cfg.frontendOrigin):
+		channel = channelInternal
+	case ok:
+		channel = known.channel
+	case emailMediums[medium]:
+		channel = channelEmail
+	default:
+		channel = channelReferral
+	}
+	return domain, source, channel
+}
+
+// Reclassifies stored clicks and moves their rolled-up counts; run after migrating
+func (cfg *apiCfg) reclassifyReferrers(ctx context.Context) (int, error) {
+	if err := cfg.loadReferrerSources(ctx); err != nil {
+		return 0, err
+	}
+	total := 0
+	after := uuid.Nil
+	for {
+		changed, last, err := cfg.reclassifyReferrerBatch(ctx, after)
+		if err != nil {
+			return total, err
+		}
+		total += changed
+		if last == uuid.Nil {
+			return total, nil
+		}
+		after = last
+	}
+}
+
+// Returns the changed count and the last click seen, uuid.Nil when none are left
+func (cfg *apiCfg) reclassifyReferrerBatch(ctx context.Context, after uuid.UUID) (int, uuid.UUID, error) {
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, uuid.Nil, err
+	}
+	defer tx.Rollback()
+	q := cfg.db.WithTx(tx)
+	// Keeps the rollup job from folding the same hours while their counts move
+	state, err := q.LockClickRollupState(ctx)
+	if err != nil {
+		return 0, uuid.Nil, err
+	}
+	clicks, err := q.ListClickReferrersAfter(ctx, database.ListClickReferrersAfterParams{
+		AfterID:   after,
+		BatchSize: referrerBackfillBatchSize,
+	})
+	if err != nil || len(clicks) == 0 {
+		return 0, uuid.Nil, err
+	}
+	changed := 0
+	for _, click := range clicks {
+		domain, source, channel := cfg.classifyReferrer(click.Referrer, click.UtmMedium)
+		if domain == click.ReferrerDomain && source == click.ReferrerSource && channel == click.ReferrerChannel {
+			continue
+		}
+		if err := q.UpdateClickReferrer(ctx, database.UpdateClickReferrerParams{
+			ID:              click.ID,
+			ReferrerDomain:  domain,
+			ReferrerSource:  source,
+			ReferrerChannel: channel,
+		}); err != nil {
+			return 0, uuid.Nil, err
+		}
+		if click.CreatedAt.Before(state.RolledUpTo) {
+			moves := []struct{ dimension, from, to string }{
+				{"referrer", click.ReferrerDomain, domain},
+				{"source", click.ReferrerSource, source},
+				{"channel", click.ReferrerChannel, channel},
+			}
+			for _, move := range moves {
+				if move.from == move.to {
+					continue
+				}
+				if err := adjustClickRollups(ctx, q, click, move.dimension, move.from, -1); err != nil {
+					return 0, uuid.Nil, err
+				}
+				if err := adjustClickRollups(ctx, q, click, move.dimension, move.to, 1); err != nil {
+					return 0, uuid.Nil, err
+				}
+			}
+		}
+		changed++
+	}
+	if err := q.DeleteEmptyRollups(ctx); err != nil {
+		return 0, uuid.Nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, uuid.Nil, err
+	}
+	return changed, clicks[len(clicks)-1].ID, nil
+}
+
+// Adds delta clicks to the rollups of one dimension value; empty values have none
+func adjustClickRollups(ctx context.Context, q *database.Queries, click database.ListClickReferrersAfterRow, dimension, value string, delta int64) error {
+	if value == "" {
+		return nil
+	}
+	var unique int64
+	if click.IsUnique {
+		unique = delta
+	}
+	if err := q.AdjustHourlyRollup(ctx, database.AdjustHourlyRollupParams{
+		ShortLinkID:  click.ShortLinkID,
+		CreatedAt:    click.CreatedAt,
+		Dimension:    dimension,
+		Value:        value,
+		Clicks:       delta,
+		UniqueClicks: unique,
+	}); err != nil {
+		return err
+	}
+	return q.AdjustDailyRollup(ctx, database.AdjustDailyRollupParams{
+		ShortLinkID:  click.ShortLinkID,
+		CreatedAt:    click.CreatedAt,
+		Dimension:    dimension,
+		Value:        value,
+		Clicks:       delta,
+		UniqueClicks: unique,
+	})
+}
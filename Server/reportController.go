@@ -0,0 +1,223 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	reportStatusOpen      = "open"
+	reportStatusResolved  = "resolved"
+	reportStatusDismissed = "dismissed"
+
+	maxReportDetails = 2000
+	maxReportNote    = 1000
+	// Anyone can report, so each address gets a handful of reports an hour
+	reportRateLimit  = 10
+	reportRateWindow = time.Hour
+)
+
+var reportCategories = map[string]bool{
+	"phishing": true,
+	"malware":  true,
+	"spam":     true,
+	"illegal":  true,
+	"other":    true,
+}
+
+var reportStatuses = map[string]bool{
+	reportStatusOpen:      true,
+	reportStatusResolved:  true,
+	reportStatusDismissed: true,
+}
+
+func abuseReportRes(report database.AbuseReport) AbuseReportRes {
+	return AbuseReportRes{
+		ID:             report.ID,
+		LinkID:         uuidPtr(report.ShortLinkID),
+		Slug:           report.Slug,
+		Category:       report.Category,
+		Details:        report.Details,
+		ReporterEmail:  report.ReporterEmail,
+		Status:         report.Status,
+		ResolutionNote: report.ResolutionNote,
+		ResolvedBy:     uuidPtr(report.ResolvedBy),
+		ResolvedAt:     nullTimePtr(report.ResolvedAt),
+		CreatedAt:      report.CreatedAt,
+	}
+}
+
+// The reporter's address is only kept as a daily hash
+func (cfg *apiCfg) ReportLink(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	var data AbuseReportReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if !reportCategories[data.Category] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category must be phishing, malware, spam, illegal or other"})
+		return
+	}
+	data.Details = strings.TrimSpace(data.Details)
+	if len([]rune(data.Details)) > maxReportDetails {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "details must be at most 2000 characters"})
+		return
+	}
+	data.Email = strings.TrimSpace(data.Email)
+	if data.Email != "" {
+		if err := validateEmail(data.Email); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	link, err := cfg.db.RetrieveShortLinkBySlug(c, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	ipHash := cfg.hashIP(c.ClientIP())
+	tx, err := cfg.conn.BeginTx(c, nil)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+	q := cfg.db.WithTx(tx)
+	// Serializes one reporter's requests so the rate limit holds
+	if err := q.LockAbuseReporter(c, ipHash); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	recent, err := q.CountRecentAbuseReportsByReporter(c, database.CountRecentAbuseReportsByReporterParams{
+		ReporterIpHash: ipHash,
+		Since:          time.Now().Add(-reportRateWindow),
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if recent >= reportRateLimit {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many reports, try again later"})
+		return
+	}
+	if _, err := q.CreateAbuseReport(c, database.CreateAbuseReportParams{
+		ShortLinkID:    uuid.NullUUID{UUID: link.ID, Valid: true},
+		Slug:           link.Slug,
+		Category:       data.Category,
+		Details:        data.Details,
+		ReporterEmail:  data.Email,
+		ReporterIpHash: ipHash,
+	}); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessRes{Success: true})
+}
+
+// The moderation queue, newest first; status and link_id narrow it down
+func (cfg *apiCfg) AdminGetReports(c *gin.Context) {
+	limit, offset, err := offsetPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params := database.ListAbuseReportsParams{
+		Status:     nullQuery(c, "status"),
+		PageLimit:  limit,
+		PageOffset: offset,
+	}
+	if params.Status.Valid && !reportStatuses[params.Status.String] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open, resolved or dismissed"})
+		return
+	}
+	if params.ShortLinkID, err = queryUUID(c, "link_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reports, err := cfg.db.ListAbuseReports(c, params)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data := []AbuseReportRes{}
+	for _, report := range reports {
+		data = append(data, abuseReportRes(report))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// Acting on the link is a separate call, so the log shows both
+func (cfg *apiCfg) AdminResolveReport(c *gin.Context) {
+	admin := sortMiddlewareAuth(c)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report id"})
+		return
+	}
+	var data ResolveReportReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if data.Status != reportStatusResolved && data.Status != reportStatusDismissed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be resolved or dismissed"})
+		return
+	}
+	data.Note = strings.TrimSpace(data.Note)
+	if len([]rune(data.Note)) > maxReportNote {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note must be at most 1000 characters"})
+		return
+	}
+	var resolved database.AbuseReport
+	err = cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		report, err := q.RetrieveAbuseReportById(c, id)
+		if err != nil {
+			return auditEntry{}, err
+		}
+		resolved, err = q.ResolveAbuseReport(c, database.ResolveAbuseReportParams{
+			ID:             id,
+			Status:         data.Status,
+			ResolutionNote: data.Note,
+			ResolvedBy:     uuid.NullUUID{UUID: admin.ID, Valid: true},
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     auditReportResolve,
+			TargetType: auditTargetReport,
+			TargetID:   id.String(),
+			Before:     gin.H{"status": report.Status, "resolution_note": report.ResolutionNote},
+			After:      gin.H{"status": resolved.Status, "resolution_note": resolved.ResolutionNote},
+		}, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "report not found"})
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, abuseReportRes(resolved))
+}
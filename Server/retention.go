@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+)
+
+const (
+	// Keeps country, referrer and UTM values; the IP is truncated and the user agent dropped
+	retentionModeAnonymize = "anonymize"
+	// Raw clicks past retention are removed together with their device rows
+	retentionModeDelete = "delete"
+
+	defaultClickRetentionDays = 90
+	maxClickRetentionDays     = 3650
+	clickRetentionInterval    = time.Hour
+	clickRetentionBatchSize   = 5000
+)
+
+type retentionPolicy struct {
+	days int
+	mode string
+}
+
+func newRetentionPolicyFromEnv() retentionPolicy {
+	policy := retentionPolicy{days: defaultClickRetentionDays, mode: retentionModeAnonymize}
+	if value := os.Getenv("CLICK_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 || days > maxClickRetentionDays {
+			log.Fatal("CLICK_RETENTION_DAYS must be between 1 and 3650")
+		}
+		policy.days = days
+	}
+	if value := os.Getenv("CLICK_RETENTION_MODE"); value != "" {
+		if value != retentionModeAnonymize && value != retentionModeDelete {
+			log.Fatal("CLICK_RETENTION_MODE must be anonymize or delete")
+		}
+		policy.mode = value
+	}
+	return policy
+}
+
+// Applies retention, in batches, to rolled-up raw clicks past the account's retention period
+func (cfg *apiCfg) pruneClicks(ctx context.Context) (int64, error) {
+	var total int64
+	for {
+		var affected int64
+		var err error
+		if cfg.retention.mode == retentionModeDelete {
+			affected, err = cfg.db.DeleteExpiredClicks(ctx, database.DeleteExpiredClicksParams{
+				DefaultDays: int32(cfg.retention.days),
+				BatchSize:   clickRetentionBatchSize,
+			})
+		} else {
+			affected, err = cfg.db.AnonymizeExpiredClicks(ctx, database.AnonymizeExpiredClicksParams{
+				DefaultDays: int32(cfg.retention.days),
+				BatchSize:   clickRetentionBatchSize,
+			})
+		}
+		if err != nil {
+			return total, err
+		}
+		total += affected
+		if affected < clickRetentionBatchSize {
+			return total, nil
+		}
+	}
+}
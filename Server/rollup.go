@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	clickRollupInterval = 5 * time.Minute
+	// Hours this far behind the watermark are refolded to catch late commits
+	clickRollupLateWindow = time.Hour
+)
+
+// Folds finished hours of clicks into the hourly and daily rollups, under a lock on the state row
+func (cfg *apiCfg) rollUpClicks(ctx context.Context) error {
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := cfg.db.WithTx(tx)
+
+	state, err := q.LockClickRollupState(ctx)
+	if err != nil {
+		return err
+	}
+	start := state.RolledUpTo.Add(-clickRollupLateWindow)
+	end := state.Horizon
+	if state.RolledUpTo.After(end) {
+		end = state.RolledUpTo
+	}
+	if err := q.DeleteHourlyRollupsBetween(ctx, database.DeleteHourlyRollupsBetweenParams{
+		StartTime: start,
+		EndTime:   end,
+	}); err != nil {
+		return err
+	}
+	if _, err := q.RollUpHourlyClicks(ctx, database.RollUpHourlyClicksParams{
+		StartTime: start,
+		EndTime:   end,
+	}); err != nil {
+		return err
+	}
+	// Days are summed again in full from their hours, which only the trailing ones changed
+	if err := q.DeleteDailyRollupsBetween(ctx, database.DeleteDailyRollupsBetweenParams{
+		StartTime: start,
+		EndTime:   end,
+	}); err != nil {
+		return err
+	}
+	if err := q.RollUpDailyClicks(ctx, database.RollUpDailyClicksParams{
+		StartTime: start,
+		EndTime:   end,
+	}); err != nil {
+		return err
+	}
+	if err := q.UpdateClickRollupState(ctx, end); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Clicks before this come from the rollups and clicks after it from the raw table
+func (cfg *apiCfg) clickRollupWatermark(c *gin.Context) (time.Time, error) {
+	state, err := cfg.db.GetClickRollupState(c)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return state.RolledUpTo, nil
+}
+
+func addRollupData(data *Analytics, rows []database.ClickDailyRollup) {
+	for _, row := range rows {
+		count := int(row.Clicks)
+		switch row.Dimension {
+		case "":
+			data.TotalClicks += count
+			data.UniqueClicks += int(row.UniqueClicks)
+			data.ClicksByDate[row.Bucket.Format("2006-01-02")] += count
+		case "country":
+			data.ByCountry[row.Value] += count
+		case "referrer":
+			data.ByReferrer[row.Value] += count
+		case "source":
+			data.BySource[row.Value] += count
+		case "channel":
+			data.ByChannel[row.Value] += count
+		case "utm_source":
+			data.UTMBreakdown.UTMSource[row.Value] += count
+		case "utm_medium":
+			data.UTMBreakdown.UTMMedium[row.Value] += count
+		case "utm_campaign":
+			data.UTMBreakdown.UTMCampaign[row.Value] += count
+		case "device_type":
+			data.DeviceSummary.DeviceType[row.Value] += count
+		case "platform":
+			data.DeviceSummary.Platform[row.Value] += count
+		case "language":
+			data.DeviceSummary.Language[row.Value] += count
+		case "resolution":
+			data.DeviceSummary.ScreenResolution[row.Value] += count
+		case "timezone":
+			data.DeviceSummary.Timezone[row.Value] += count
+		case "user_agent":
+			data.DeviceSummary.UserAgents[row.Value] += count
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // schedules name IANA zones, which minimal images don't ship
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	scheduleExpiryInterval = time.Minute
+	maxScheduleWindows     = 20
+)
+
+// Source of the current time, so schedule evaluation can be driven by a fixed clock
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Minutes since local midnight; an end not after the start runs into the next day
+type scheduleWindow struct {
+	days  [7]bool
+	start int
+	end   int
+}
+
+type linkSchedule struct {
+	location     *time.Location
+	activateAt   sql.NullTime
+	deactivateAt sql.NullTime
+	windows      []scheduleWindow
+}
+
+// Parses "HH:MM"; "24:00" is allowed as the end of a day
+func parseClockTime(value string) (int, error) {
+	hours, minutes, found := strings.Cut(value, ":")
+	h, err1 := strconv.Atoi(hours)
+	m, err2 := strconv.Atoi(minutes)
+	if !found || err1 != nil || err2 != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("%q is not a valid HH:MM time", value)
+	}
+	return h*60 + m, nil
+}
+
+func parseScheduleWindows(windows []ScheduleWindow) ([]scheduleWindow, error) {
+	if len(windows) > maxScheduleWindows {
+		return nil, errors.New("A schedule can have at most 20 windows")
+	}
+	parsed := []scheduleWindow{}
+	for _, w := range windows {
+		if len(w.Days) == 0 {
+			return nil, errors.New("Every window needs at least one day")
+		}
+		var window scheduleWindow
+		for _, day := range w.Days {
+			if day < 0 || day > 6 {
+				return nil, errors.New("days must be between 0 (Sunday) and 6 (Saturday)")
+			}
+			window.days[day] = true
+		}
+		var err error
+		if window.start, err = parseClockTime(w.Start); err != nil {
+			return nil, err
+		}
+		if window.end, err = parseClockTime(w.End); err != nil {
+			return nil, err
+		}
+		if window.start == 24*60 {
+			return nil, errors.New("start must be before 24:00")
+		}
+		parsed = append(parsed, window)
+	}
+	return parsed, nil
+}
+
+func parseLinkSchedule(row database.LinkSchedule) (linkSchedule, error) {
+	location, err := time.LoadLocation(row.Timezone)
+	if err != nil {
+		return linkSchedule{}, err
+	}
+	var windows []ScheduleWindow
+	if err := json.Unmarshal(row.Windows, &windows); err != nil {
+		return linkSchedule{}, err
+	}
+	parsed, err := parseScheduleWindows(windows)
+	if err != nil {
+		return linkSchedule{}, err
+	}
+	return linkSchedule{
+		location:     location,
+		activateAt:   row.ActivateAt,
+		deactivateAt: row.DeactivateAt,
+		windows:      parsed,
+	}, nil
+}
+
+// Windows are compared against local wall-clock time, so they hold across DST changes
+func (s linkSchedule) isOpen(now time.Time) bool {
+	if s.activateAt.Valid && now.Before(s.activateAt.Time) {
+		return false
+	}
+	if s.deactivateAt.Valid && !now.Before(s.deactivateAt.Time) {
+		return false
+	}
+	if len(s.windows) == 0 {
+		return true
+	}
+	local := now.In(s.location)
+	minute := local.Hour()*60 + local.Minute()
+	today := int(local.Weekday())
+	yesterday := (today + 6) % 7
+	for _, w := range s.windows {
+		if w.start < w.end {
+			if w.days[today] && minute >= w.start && minute < w.end {
+				return true
+			}
+			continue
+		}
+		// Overnight: before midnight is today's window, after it yesterday's
+		if (w.days[today] && minute >= w.start) || (w.days[yesterday] && minute < w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// RFC 3339, or local "YYYY-MM-DDTHH:MM"; gap times move forward, repeated ones mean the first
+func parseScheduleTime(value string, location *time.Location) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		const layout = "2006-01-02T15:04"
+		wall, err := time.ParseInLocation(layout, value, time.UTC)
+		if err != nil {
+			return sql.NullTime{}, err
+		}
+		// Try the offsets from both sides; in a gap the earlier one moves the time forward
+		_, before := wall.Add(-24 * time.Hour).In(location).Zone()
+		_, after := wall.Add(24 * time.Hour).In(location).Zone()
+		t = wall.Add(-time.Duration(before) * time.Second)
+		if later := wall.Add(-time.Duration(after) * time.Second); t.In(location).Format(layout) != value && later.In(location).Format(layout) == value {
+			t = later
+		}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
+
+// Returns the link's schedule, or nil when it has none
+func (cfg *apiCfg) linkScheduleFor(c *gin.Context, link database.ShortLink) (*database.LinkSchedule, error) {
+	schedule, err := cfg.db.GetLinkSchedule(c, link.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// A schedule that can't be read counts as closed
+func scheduleOpen(schedule database.LinkSchedule, now time.Time) bool {
+	parsed, err := parseLinkSchedule(schedule)
+	if err != nil {
+		log.Printf("Failed to parse link schedule: %v", err)
+		return false
+	}
+	return parsed.isOpen(now)
+}
+
+// Emits link.expired once for every schedule whose deactivation time has passed
+func (cfg *apiCfg) notifyExpiredLinks(ctx context.Context) error {
+	ids, err := cfg.db.ClaimExpiredLinkSchedules(ctx, cfg.clock.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		link, err := cfg.db.RetrieveShortLinkById(ctx, id)
+		if err != nil {
+			log.Printf("Failed to load expired link: %v", err)
+			continue
+		}
+		if link.DeletedAt.Valid {
+			continue
+		}
+		cfg.emitLinkEvent(ctx, webhookLinkExpired, link)
+	}
+	return nil
+}
+
+func scheduleRes(schedule database.LinkSchedule, now time.Time) ScheduleRes {
+	windows := []ScheduleWindow{}
+	_ = json.Unmarshal(schedule.Windows, &windows)
+	return ScheduleRes{
+		Timezone:     schedule.Timezone,
+		ActivateAt:   nullTimePtr(schedule.ActivateAt),
+		DeactivateAt: nullTimePtr(schedule.DeactivateAt),
+		Windows:      windows,
+		FallbackURL:  schedule.FallbackUrl,
+		IsOpen:       scheduleOpen(schedule, now),
+	}
+}
+
+func (cfg *apiCfg) ownedLinkFromParam(c *gin.Context) (database.ShortLink, bool) {
+	user := sortMiddlewareAuth(c)
+	link, err := cfg.db.RetrieveShortLinkBySlugNUserId(c, database.RetrieveShortLinkBySlugNUserIdParams{
+		UserID: user.ID,
+		Slug:   c.Param("slug"),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+			return database.ShortLink{}, false
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return database.ShortLink{}, false
+	}
+	return link, true
+}
+
+func (cfg *apiCfg) GetLinkSchedule(c *gin.Context) {
+	link, ok := cfg.ownedLinkFromParam(c)
+	if !ok {
+		return
+	}
+	schedule, err := cfg.linkScheduleFor(c, link)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if schedule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		return
+	}
+	c.JSON(http.StatusOK, scheduleRes(*schedule, cfg.clock.Now()))
+}
+
+// The manual on/off switch still applies on top of the schedule
+func (cfg *apiCfg) PutLinkSchedule(c *gin.Context) {
+	link, ok := cfg.ownedLinkFromParam(c)
+	if !ok {
+		return
+	}
+	var data ScheduleReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	data.Timezone = defaultString(strings.TrimSpace(data.Timezone), "UTC")
+	location, err := time.LoadLocation(data.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timezone must be an IANA time zone such as Europe/Berlin"})
+		return
+	}
+	activateAt, err := parseScheduleTime(data.ActivateAt, location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "activate_at must be an RFC 3339 timestamp or a local YYYY-MM-DDTHH:MM time"})
+		return
+	}
+	deactivateAt, err := parseScheduleTime(data.DeactivateAt, location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "deactivate_at must be an RFC 3339 timestamp or a local YYYY-MM-DDTHH:MM time"})
+		return
+	}
+	if activateAt.Valid && deactivateAt.Valid && !deactivateAt.Time.After(activateAt.Time) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "deactivate_at must be after activate_at"})
+		return
+	}
+	if data.Windows == nil {
+		data.Windows = []ScheduleWindow{}
+	}
+	if _, err := parseScheduleWindows(data.Windows); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data.FallbackURL = strings.TrimSpace(data.FallbackURL)
+	if data.FallbackURL != "" {
+		if err := validateAbsoluteURL("fallback_url", data.FallbackURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	windows, err := json.Marshal(data.Windows)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	schedule, err := cfg.db.UpsertLinkSchedule(c, database.UpsertLinkScheduleParams{
+		ShortLinkID:  link.ID,
+		Timezone:     data.Timezone,
+		ActivateAt:   activateAt,
+		DeactivateAt: deactivateAt,
+		Windows:      windows,
+		FallbackUrl:  data.FallbackURL,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	// The fallback URL is somewhere visitors can be sent too
+	cfg.screenLinkOnSave(c, link)
+	c.JSON(http.StatusOK, scheduleRes(schedule, cfg.clock.Now()))
+}
+
+func (cfg *apiCfg) DeleteLinkSchedule(c *gin.Context) {
+	link, ok := cfg.ownedLinkFromParam(c)
+	if !ok {
+		return
+	}
+	rows, err := cfg.db.DeleteLinkSchedule(c, link.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		return
+	}
+	cfg.screenLinkOnSave(c, link)
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// A clock stopped at one instant
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+func TestParseScheduleTime(t *testing.T) {
+	tests := []struct {
+		name  string
+		zone  string
+		value string
+		want  string
+	}{
+		{"rfc3339 ignores the zone", "Europe/Berlin", "2026-03-29T02:30:00Z", "2026-03-29T02:30:00Z"},
+		{"berlin ordinary", "Europe/Berlin", "2026-03-28T02:30", "2026-03-28T01:30:00Z"},
+		{"berlin gap moves forward", "Europe/Berlin", "2026-03-29T02:30", "2026-03-29T01:30:00Z"},
+		{"berlin after the gap", "Europe/Berlin", "2026-03-29T10:00", "2026-03-29T08:00:00Z"},
+		{"berlin overlap takes the first", "Europe/Berlin", "2026-10-25T02:30", "2026-10-25T00:30:00Z"},
+		{"berlin after the overlap", "Europe/Berlin", "2026-10-25T10:00", "2026-10-25T09:00:00Z"},
+		{"new york gap moves forward", "America/New_York", "2026-03-08T02:30", "2026-03-08T07:30:00Z"},
+		{"new york after the gap", "America/New_York", "2026-03-08T12:00", "2026-03-08T16:00:00Z"},
+		{"new york overlap takes the first", "America/New_York", "2026-11-01T01:30", "2026-11-01T05:30:00Z"},
+		{"new york after the overlap", "America/New_York", "2026-11-01T12:00", "2026-11-01T17:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseScheduleTime(tt.value, mustLoadLocation(t, tt.zone))
+			if err != nil {
+				t.Fatalf("parseScheduleTime(%q): %v", tt.value, err)
+			}
+			if want, _ := time.Parse(time.RFC3339, tt.want); !got.Valid || !got.Time.Equal(want) {
+				t.Fatalf("parseScheduleTime(%q) = %v, want %v", tt.value, got.Time, want)
+			}
+		})
+	}
+}
+
+// Serves one active link with the given schedule to a config whose clock is stopped at now
+func newScheduleCfg(t *testing.T, now time.Time, schedule database.LinkSchedule) (*fakeDB, *apiCfg, database.ShortLink) {
+	t.Helper()
+	fake, conn := newFakeDB(t)
+	link := database.ShortLink{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		Slug:        "scheduled",
+		OriginalUrl: "https://example.com/",
+		IsActive:    sql.NullBool{Bool: true, Valid: true},
+		ExtraParams: json.RawMessage("{}"),
+		CreatedAt:   now,
+	}
+	schedule.ShortLinkID = link.ID
+	schedule.CreatedAt = now
+	fake.returns("RetrieveShortLinkBySlug", link)
+	fake.returns("RetrieveShortLinkBySlugNUserId", link)
+	fake.returns("RetrieveShortLinkById", link)
+	fake.returns("IsUserSuspended", false)
+	fake.returns("GetLinkSchedule", schedule)
+	return fake, &apiCfg{db: database.New(conn), conn: conn, clock: fixedClock(now)}, link
+}
+
+// Returns linkState's kind and GetLinkSchedule's is_open for the link
+func checkSchedule(t *testing.T, cfg *apiCfg, link database.ShortLink) (string, bool) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/"+link.Slug, nil)
+	_, kind, _, err := cfg.linkState(c, link.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/link/"+link.Slug+"/schedule", nil)
+	c.Params = gin.Params{{Key: "slug", Value: link.Slug}}
+	c.Set("currentUser", database.User{ID: link.UserID})
+	cfg.GetLinkSchedule(c)
+	var res ScheduleRes
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("schedule response %d %s: %v", w.Code, w.Body, err)
+	}
+	return kind, res.IsOpen
+}
+
+func TestScheduleIsOpenAcrossDST(t *testing.T) {
+	const sunday, saturday = 0, 6
+	window := func(day int, start, end string) ScheduleWindow {
+		return ScheduleWindow{Days: []int{day}, Start: start, End: end}
+	}
+	tests := []struct {
+		name   string
+		zone   string
+		window ScheduleWindow
+		now    string
+		want   bool
+	}{
+		// Berlin springs forward at 02:00 on 2026-03-29 and falls back at 03:00 on 2026-10-25
+		{"berlin gap day before opening", "Europe/Berlin", window(sunday, "09:00", "17:00"), "2026-03-29T06:59:00Z", false},
+		{"berlin gap day at opening", "Europe/Berlin", window(sunday, "09:00", "17:00"), "2026-03-29T07:00:00Z", true},
+		{"berlin gap day at closing", "Europe/Berlin", window(sunday, "09:00", "17:00"), "2026-03-29T15:00:00Z", false},
+		{"berlin overnight into the gap", "Europe/Berlin", window(saturday, "22:00", "06:00"), "2026-03-29T01:30:00Z", true},
+		{"berlin overnight ends on local time", "Europe/Berlin", window(saturday, "22:00", "06:00"), "2026-03-29T04:00:00Z", false},
+		{"berlin overlap day at opening", "Europe/Berlin", window(sunday, "09:00", "17:00"), "2026-10-25T08:00:00Z", true},
+		{"berlin overlap day before opening", "Europe/Berlin", window(sunday, "09:00", "17:00"), "2026-10-25T07:59:00Z", false},
+		{"berlin overnight through the overlap", "Europe/Berlin", window(saturday, "22:00", "06:00"), "2026-10-25T01:30:00Z", true},
+		{"berlin overnight past the overlap", "Europe/Berlin", window(saturday, "22:00", "06:00"), "2026-10-25T05:00:00Z", false},
+		// New York springs forward at 02:00 on 2026-03-08 and falls back at 02:00 on 2026-11-01
+		{"new york window inside the gap", "America/New_York", window(sunday, "02:00", "03:00"), "2026-03-08T06:59:00Z", false},
+		{"new york window after the gap", "America/New_York", window(sunday, "02:00", "03:00"), "2026-03-08T07:00:00Z", false},
+		{"new york gap day at opening", "America/New_York", window(sunday, "09:00", "17:00"), "2026-03-08T13:00:00Z", true},
+		{"new york overnight first 01:30", "America/New_York", window(saturday, "23:00", "02:00"), "2026-11-01T05:30:00Z", true},
+		{"new york overnight second 01:30", "America/New_York", window(saturday, "23:00", "02:00"), "2026-11-01T06:30:00Z", true},
+		{"new york overnight after the overlap", "America/New_York", window(saturday, "23:00", "02:00"), "2026-11-01T07:00:00Z", false},
+		{"new york overlap day at opening", "America/New_York", window(sunday, "09:00", "17:00"), "2026-11-01T14:00:00Z", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			windows, _ := json.Marshal([]ScheduleWindow{tt.window})
+			_, cfg, link := newScheduleCfg(t, now, database.LinkSchedule{Timezone: tt.zone, Windows: windows})
+			kind, open := checkSchedule(t, cfg, link)
+			wantKind := fallbackExpired
+			if tt.want {
+				wantKind = ""
+			}
+			if kind != wantKind {
+				t.Fatalf("linkState at %s = %q, want %q", now.In(mustLoadLocation(t, tt.zone)), kind, wantKind)
+			}
+			if open != tt.want {
+				t.Fatalf("schedule is_open at %s = %v, want %v", now.In(mustLoadLocation(t, tt.zone)), open, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleDeactivatesAcrossDST(t *testing.T) {
+	// 02:30 happens twice in Berlin on 2026-10-25; the deactivation is the first, 00:30 UTC
+	location := mustLoadLocation(t, "Europe/Berlin")
+	deactivateAt, err := parseScheduleTime("2026-10-25T02:30", location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := database.LinkSchedule{Timezone: location.String(), DeactivateAt: deactivateAt, Windows: json.RawMessage("[]")}
+	for _, tt := range []struct {
+		now  string
+		want bool
+	}{
+		{"2026-10-25T00:29:00Z", true},
+		{"2026-10-25T00:30:00Z", false},
+		{"2026-10-25T01:30:00Z", false},
+	} {
+		now, _ := time.Parse(time.RFC3339, tt.now)
+		_, cfg, link := newScheduleCfg(t, now, schedule)
+		if kind, open := checkSchedule(t, cfg, link); open != tt.want || (kind == "") != tt.want {
+			t.Fatalf("at %s: kind %q is_open %v, want open %v", tt.now, kind, open, tt.want)
+		}
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2026-10-25T00:30:00Z")
+	fake, cfg, link := newScheduleCfg(t, now, schedule)
+	fake.returns("ClaimExpiredLinkSchedules", link.ID)
+	if err := cfg.notifyExpiredLinks(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	claims := fake.called("ClaimExpiredLinkSchedules")
+	if len(claims) != 1 || !claims[0][0].(time.Time).Equal(now) {
+		t.Fatalf("claimed expired schedules with %v, want the clock's %s", claims, now)
+	}
+	if listed := fake.called("ListWebhooksForEvent"); len(listed) != 1 || listed[0][1] != webhookLinkExpired {
+		t.Fatalf("webhooks listed for %v, want one %s lookup", listed, webhookLinkExpired)
+	}
+}
@@ -0,0 +1,31 @@
+-- name: CreateAbuseReport :one
+INSERT INTO abuse_reports(id,short_link_id,slug,category,details,reporter_email,reporter_ip_hash,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW()
+) RETURNING *;
+-- name: LockAbuseReporter :exec
+SELECT pg_advisory_xact_lock(hashtextextended(@reporter_ip_hash::text, 0));
+-- name: CountRecentAbuseReportsByReporter :one
+SELECT COUNT(*) FROM abuse_reports
+WHERE reporter_ip_hash = @reporter_ip_hash AND created_at >= @since::timestamp;
+-- name: ListAbuseReports :many
+SELECT * FROM abuse_reports
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+  AND (sqlc.narg('short_link_id')::uuid IS NULL OR short_link_id = sqlc.narg('short_link_id')::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit::int OFFSET @page_offset::int;
+-- name: RetrieveAbuseReportById :one
+SELECT * FROM abuse_reports
+WHERE id = $1;
+-- name: ResolveAbuseReport :one
+UPDATE abuse_reports
+SET status = $2, resolution_note = $3, resolved_by = $4, resolved_at = NOW()
+WHERE id = $1
+RETURNING *;
\ No newline at end of file
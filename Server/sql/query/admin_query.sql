@@ -0,0 +1,72 @@
+-- name: SetUserRole :execrows
+UPDATE users
+SET role = $2, updated_at = NOW()
+WHERE email = $1;
+-- name: AdminListUsers :many
+SELECT
+  users.id, users.name, users.email, users.role, users.email_verified, users.suspended_at, users.suspension_reason, users.created_at,
+  (SELECT COUNT(*) FROM short_links WHERE short_links.user_id = users.id)::BIGINT AS link_count
+FROM users
+WHERE (sqlc.narg('search')::text IS NULL
+    OR users.email ILIKE '%' || replace(replace(replace(sqlc.narg('search')::text, '\', '\\'), '%', '\%'), '_', '\_') || '%'
+    OR users.name ILIKE '%' || replace(replace(replace(sqlc.narg('search')::text, '\', '\\'), '%', '\%'), '_', '\_') || '%')
+  AND (sqlc.narg('suspended')::boolean IS NULL OR (users.suspended_at IS NOT NULL) = sqlc.narg('suspended')::boolean)
+ORDER BY users.created_at DESC, users.id DESC
+LIMIT @page_limit::int OFFSET @page_offset::int;
+-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = NOW(), suspension_reason = $2, tokens_valid_after = FLOOR(EXTRACT(EPOCH FROM NOW()))::BIGINT, updated_at = NOW()
+WHERE id = $1 AND suspended_at IS NULL
+RETURNING *;
+-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, suspension_reason = '', updated_at = NOW()
+WHERE id = $1 AND suspended_at IS NOT NULL
+RETURNING *;
+-- name: IsUserSuspended :one
+SELECT (suspended_at IS NOT NULL)::boolean AS suspended FROM users
+WHERE id = $1;
+-- name: AdminListLinks :many
+SELECT short_links.*, users.email AS owner_email
+FROM short_links
+JOIN users ON users.id = short_links.user_id
+WHERE (sqlc.narg('search')::text IS NULL
+    OR short_links.slug ILIKE '%' || replace(replace(replace(sqlc.narg('search')::text, '\', '\\'), '%', '\%'), '_', '\_') || '%'
+    OR short_links.original_url ILIKE '%' || replace(replace(replace(sqlc.narg('search')::text, '\', '\\'), '%', '\%'), '_', '\_') || '%')
+  AND (sqlc.narg('user_id')::uuid IS NULL OR short_links.user_id = sqlc.narg('user_id')::uuid)
+  AND (sqlc.narg('status')::text IS NULL
+    OR (sqlc.narg('status')::text = 'quarantined' AND short_links.quarantined_at IS NOT NULL)
+    OR (sqlc.narg('status')::text = 'blocked' AND short_links.blocked_at IS NOT NULL)
+    OR (sqlc.narg('status')::text = 'disabled' AND NOT short_links.is_active)
+    OR (sqlc.narg('status')::text = 'deleted' AND short_links.deleted_at IS NOT NULL)
+    OR (sqlc.narg('status')::text = 'active' AND short_links.is_active AND short_links.blocked_at IS NULL
+      AND short_links.quarantined_at IS NULL AND short_links.deleted_at IS NULL))
+ORDER BY short_links.created_at DESC, short_links.id DESC
+LIMIT @page_limit::int OFFSET @page_offset::int;
+-- name: BlockShortLink :one
+UPDATE short_links
+SET blocked_at = NOW(), blocked_reason = $2
+WHERE id = $1 AND blocked_at IS NULL
+RETURNING *;
+-- name: UnblockShortLink :one
+UPDATE short_links
+SET blocked_at = NULL, blocked_reason = ''
+WHERE id = $1 AND blocked_at IS NOT NULL
+RETURNING *;
+-- name: ClearShortLinkQuarantine :one
+UPDATE short_links
+SET quarantined_at = NULL, quarantine_reason = '', quarantined_by = ''
+WHERE id = $1 AND quarantined_at IS NOT NULL
+RETURNING *;
+-- name: GetSystemStats :one
+SELECT
+  (SELECT COUNT(*) FROM users)::BIGINT AS users,
+  (SELECT COUNT(*) FROM users WHERE suspended_at IS NOT NULL)::BIGINT AS suspended_users,
+  (SELECT COUNT(*) FROM users WHERE created_at >= NOW() - INTERVAL '7 days')::BIGINT AS new_users_7d,
+  (SELECT COUNT(*) FROM short_links)::BIGINT AS links,
+  (SELECT COUNT(*) FROM short_links WHERE is_active AND deleted_at IS NULL)::BIGINT AS active_links,
+  (SELECT COUNT(*) FROM short_links WHERE quarantined_at IS NOT NULL)::BIGINT AS quarantined_links,
+  (SELECT COUNT(*) FROM short_links WHERE blocked_at IS NOT NULL)::BIGINT AS blocked_links,
+  (SELECT COUNT(*) FROM clicks WHERE created_at >= NOW() - INTERVAL '24 hours')::BIGINT AS clicks_24h,
+  (SELECT COUNT(*) FROM abuse_reports WHERE status = 'open')::BIGINT AS open_reports,
+  (SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'pending')::BIGINT AS pending_webhook_deliveries;
\ No newline at end of file
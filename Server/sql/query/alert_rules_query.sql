@@ -0,0 +1,77 @@
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules(id,user_id,short_link_id,type,threshold,window_minutes,channel,checked_until,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW(),
+    NOW()
+) RETURNING *;
+-- name: ListAlertRulesByUserId :many
+SELECT alert_rules.*, short_links.slug
+FROM alert_rules
+JOIN short_links ON short_links.id = alert_rules.short_link_id
+WHERE alert_rules.user_id = $1
+ORDER BY alert_rules.created_at DESC;
+-- name: SetAlertRuleMuted :execrows
+UPDATE alert_rules
+SET is_muted = $3, checked_until = CASE WHEN $3 THEN checked_until ELSE NOW() END
+WHERE user_id = $1 AND id = $2;
+-- name: DeleteAlertRuleByUserIdANDId :execrows
+DELETE FROM alert_rules
+WHERE user_id = $1 AND id = $2;
+-- name: LockActiveAlertRules :many
+SELECT alert_rules.*, short_links.slug, short_links.created_at AS link_created_at, users.email, users.name
+FROM alert_rules
+JOIN short_links ON short_links.id = alert_rules.short_link_id
+JOIN users ON users.id = alert_rules.user_id
+WHERE NOT alert_rules.is_muted AND short_links.deleted_at IS NULL
+FOR UPDATE OF alert_rules SKIP LOCKED;
+-- name: UpdateAlertRuleCheck :exec
+UPDATE alert_rules
+SET checked_until = @checked_until, last_triggered_at = COALESCE(sqlc.narg('triggered_at')::timestamp, last_triggered_at)
+WHERE id = @id;
+-- name: GetAlertClock :one
+SELECT rolled_up_to, LOCALTIMESTAMP::timestamp AS now FROM click_rollup_state;
+-- name: CountLinkClicksBetween :one
+WITH bounds AS (
+  SELECT
+    date_trunc('hour', @start_time::timestamp + interval '1 hour' - interval '1 microsecond') AS rolled_from,
+    date_trunc('hour', LEAST(@end_time::timestamp, @rolled_up_to::timestamp)) AS rolled_until
+)
+SELECT (
+  COALESCE((
+    SELECT SUM(click_hourly_rollups.clicks) FROM click_hourly_rollups, bounds
+    WHERE click_hourly_rollups.short_link_id = @short_link_id AND click_hourly_rollups.dimension = ''
+      AND click_hourly_rollups.bucket >= bounds.rolled_from
+      AND click_hourly_rollups.bucket < bounds.rolled_until
+  ), 0) + (
+    SELECT COUNT(clicks.id) FROM clicks, bounds
+    WHERE clicks.short_link_id = @short_link_id
+      AND clicks.created_at >= @start_time::timestamp
+      AND clicks.created_at < @end_time::timestamp
+      AND NOT (clicks.created_at >= bounds.rolled_from AND clicks.created_at < bounds.rolled_until)
+  )
+)::bigint AS total_clicks;
+-- name: ListNewClickCountries :many
+SELECT clicks.country FROM clicks
+WHERE clicks.short_link_id = @short_link_id
+  AND clicks.created_at >= @start_time::timestamp AND clicks.created_at < @end_time::timestamp
+  AND clicks.country NOT IN ('', 'Unknown')
+  AND NOT EXISTS (
+    SELECT 1 FROM clicks earlier
+    WHERE earlier.short_link_id = clicks.short_link_id AND earlier.country = clicks.country
+      AND earlier.created_at < @start_time::timestamp
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM click_hourly_rollups
+    WHERE click_hourly_rollups.short_link_id = clicks.short_link_id
+      AND click_hourly_rollups.dimension = 'country' AND click_hourly_rollups.value = clicks.country
+      AND click_hourly_rollups.bucket < date_trunc('hour', @start_time::timestamp)
+  )
+GROUP BY clicks.country
+ORDER BY clicks.country;
\ No newline at end of file
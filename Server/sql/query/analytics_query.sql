@@ -0,0 +1,97 @@
+-- name: AccountClickTotals :one
+SELECT
+  COALESCE(SUM(counts.total_clicks), 0)::bigint AS total_clicks,
+  COALESCE(SUM(counts.unique_clicks), 0)::bigint AS unique_clicks,
+  COUNT(DISTINCT counts.short_link_id) AS active_links
+FROM (
+  SELECT click_hourly_rollups.short_link_id, click_hourly_rollups.clicks AS total_clicks, click_hourly_rollups.unique_clicks
+  FROM click_hourly_rollups
+  JOIN short_links ON short_links.id = click_hourly_rollups.short_link_id
+  WHERE short_links.user_id = @user_id AND short_links.deleted_at IS NULL AND click_hourly_rollups.dimension = ''
+    AND click_hourly_rollups.bucket >= @start_time::timestamp
+    AND click_hourly_rollups.bucket < LEAST(@end_time::timestamp, @rolled_up_to::timestamp)
+  UNION ALL
+  SELECT clicks.short_link_id, 1, CASE WHEN clicks.is_unique THEN 1 ELSE 0 END
+  FROM clicks
+  JOIN short_links ON short_links.id = clicks.short_link_id
+  WHERE short_links.user_id = @user_id AND short_links.deleted_at IS NULL
+    AND clicks.created_at >= GREATEST(@start_time::timestamp, @rolled_up_to::timestamp)
+    AND clicks.created_at < @end_time::timestamp
+) counts;
+-- name: AccountClicksByDay :many
+SELECT
+  counts.day::timestamp AS day,
+  SUM(counts.total_clicks)::bigint AS total_clicks,
+  SUM(counts.unique_clicks)::bigint AS unique_clicks
+FROM (
+  SELECT date_trunc('day', click_hourly_rollups.bucket) AS day, click_hourly_rollups.clicks AS total_clicks, click_hourly_rollups.unique_clicks
+  FROM click_hourly_rollups
+  JOIN short_links ON short_links.id = click_hourly_rollups.short_link_id
+  WHERE short_links.user_id = @user_id AND short_links.deleted_at IS NULL AND click_hourly_rollups.dimension = ''
+    AND click_hourly_rollups.bucket >= @start_time::timestamp
+    AND click_hourly_rollups.bucket < LEAST(@end_time::timestamp, @rolled_up_to::timestamp)
+  UNION ALL
+  SELECT date_trunc('day', clicks.created_at), 1, CASE WHEN clicks.is_unique THEN 1 ELSE 0 END
+  FROM clicks
+  JOIN short_links ON short_links.id = clicks.short_link_id
+  WHERE short_links.user_id = @user_id AND short_links.deleted_at IS NULL
+    AND clicks.created_at >= GREATEST(@start_time::timestamp, @rolled_up_to::timestamp)
+    AND clicks.created_at < @end_time::timestamp
+) counts
+GROUP BY counts.day
+ORDER BY counts.day;
+-- name: AccountTopLinks :many
+SELECT
+  short_links.slug, short_links.original_url, short_links.title,
+  SUM(counts.total_clicks)::bigint AS total_clicks,
+  SUM(counts.unique_clicks)::bigint AS unique_clicks
+FROM (
+  SELECT click_hourly_rollups.short_link_id, click_hourly_rollups.clicks AS total_clicks, click_hourly_rollups.unique_clicks
+  FROM click_hourly_rollups
+  WHERE click_hourly_rollups.dimension = ''
+    AND click_hourly_rollups.bucket >= @start_time::timestamp
+    AND click_hourly_rollups.bucket < LEAST(@end_time::timestamp, @rolled_up_to::timestamp)
+  UNION ALL
+  SELECT clicks.short_link_id, 1, CASE WHEN clicks.is_unique THEN 1 ELSE 0 END
+  FROM clicks
+  WHERE clicks.created_at >= GREATEST(@start_time::timestamp, @rolled_up_to::timestamp)
+    AND clicks.created_at < @end_time::timestamp
+) counts
+JOIN short_links ON short_links.id = counts.short_link_id
+WHERE short_links.user_id = @user_id AND short_links.deleted_at IS NULL
+GROUP BY short_links.id
+ORDER BY total_clicks DESC, short_links.slug
+LIMIT @row_limit::int;
+-- name: AccountTopDimension :many
+SELECT
+  counts.value::text AS value,
+  SUM(counts.total_clicks)::bigint AS total_clicks
+FROM (
+  SELECT click_hourly_rollups.value, click_hourly_rollups.clicks AS total_clicks
+  FROM click_hourly_rollups
+  JOIN short_links ON short_links.id = click_hourly_rollups.short_link_id
+  WHERE short_links.user_id = @user_id AND short_links.deleted_at IS NULL AND click_hourly_rollups.dimension = @dimension::text
+    AND click_hourly_rollups.bucket >= @start_time::timestamp
+    AND click_hourly_rollups.bucket < LEAST(@end_time::timestamp, @rolled_up_to::timestamp)
+  UNION ALL
+  SELECT
+    CASE @dimension::text
+      WHEN 'country' THEN clicks.country
+      WHEN 'referrer' THEN clicks.referrer_domain
+      WHEN 'source' THEN clicks.referrer_source
+      WHEN 'channel' THEN clicks.referrer_channel
+      WHEN 'device_type' THEN COALESCE(devices.device_type, '')
+      WHEN 'platform' THEN COALESCE(devices.platform, '')
+    END,
+    1
+  FROM clicks
+  JOIN short_links ON short_links.id = clicks.short_link_id
+  LEFT JOIN devices ON devices.click_id = clicks.id
+  WHERE short_links.user_id = @user_id AND short_links.deleted_at IS NULL
+    AND clicks.created_at >= GREATEST(@start_time::timestamp, @rolled_up_to::timestamp)
+    AND clicks.created_at < @end_time::timestamp
+) counts
+WHERE counts.value <> ''
+GROUP BY counts.value
+ORDER BY total_clicks DESC, counts.value
+LIMIT @row_limit::int;
\ No newline at end of file
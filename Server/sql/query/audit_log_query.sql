@@ -0,0 +1,32 @@
+-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log(id,actor_id,account_id,action,target_type,target_id,before,after,ip_address,user_agent,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    NOW()
+);
+-- name: ListAuditLog :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg('actor_id')::uuid IS NULL OR actor_id = sqlc.narg('actor_id')::uuid)
+  AND (sqlc.narg('account_id')::uuid IS NULL OR account_id = sqlc.narg('account_id')::uuid)
+  AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action')::text)
+  AND (sqlc.narg('target_type')::text IS NULL OR target_type = sqlc.narg('target_type')::text)
+  AND (sqlc.narg('target_id')::text IS NULL OR target_id = sqlc.narg('target_id')::text)
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after')::timestamp)
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before')::timestamp)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit::int OFFSET @page_offset::int;
+-- name: ExportLinkRevisionsByAccountId :many
+SELECT * FROM audit_log
+WHERE account_id = $1 AND target_type = 'link'
+ORDER BY created_at, id;
+-- name: ScrubAuditLogAccount :exec
+SELECT scrub_audit_log_account(@account_id::uuid);
\ No newline at end of file
@@ -0,0 +1,32 @@
+-- name: CreateCampaign :one
+INSERT INTO campaigns(id,user_id,name,utm_source,utm_medium,utm_campaign,starts_at,ends_at,budget_note,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    NOW()
+) RETURNING *;
+-- name: ListCampaignsByUserId :many
+SELECT campaigns.*, (
+  SELECT COUNT(short_links.id) FROM short_links
+  WHERE short_links.campaign_id = campaigns.id AND short_links.deleted_at IS NULL
+)::BIGINT AS link_count
+FROM campaigns
+WHERE campaigns.user_id = $1
+ORDER BY campaigns.created_at DESC;
+-- name: RetrieveCampaignByUserIdANDId :one
+SELECT * FROM campaigns
+WHERE user_id = $1 AND id = $2;
+-- name: UpdateCampaign :execrows
+UPDATE campaigns
+SET name = $3, utm_source = $4, utm_medium = $5, utm_campaign = $6, starts_at = $7, ends_at = $8, budget_note = $9, updated_at = NOW()
+WHERE user_id = $1 AND id = $2;
+-- name: DeleteCampaignByUserIdANDId :execrows
+DELETE FROM campaigns
+WHERE user_id = $1 AND id = $2;
\ No newline at end of file
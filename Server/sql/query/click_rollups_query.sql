@@ -0,0 +1,107 @@
+-- name: GetClickRollupState :one
+SELECT * FROM click_rollup_state;
+-- name: LockClickRollupState :one
+SELECT
+  rolled_up_to,
+  date_trunc('hour', LOCALTIMESTAMP - interval '1 minute')::timestamp AS horizon
+FROM click_rollup_state
+FOR UPDATE;
+-- name: UpdateClickRollupState :exec
+UPDATE click_rollup_state
+SET rolled_up_to = $1, updated_at = NOW();
+-- name: DeleteHourlyRollupsBetween :exec
+DELETE FROM click_hourly_rollups
+WHERE bucket >= @start_time::timestamp AND bucket < @end_time::timestamp;
+-- name: DeleteDailyRollupsBetween :exec
+DELETE FROM click_daily_rollups
+WHERE bucket >= date_trunc('day', @start_time::timestamp) AND bucket < @end_time::timestamp;
+-- name: RollUpHourlyClicks :execrows
+INSERT INTO click_hourly_rollups(short_link_id, bucket, dimension, value, clicks, unique_clicks)
+SELECT
+  clicks.short_link_id,
+  date_trunc('hour', clicks.created_at),
+  dims.dimension,
+  dims.value,
+  COUNT(clicks.id),
+  COUNT(clicks.id) FILTER (WHERE clicks.is_unique)
+FROM clicks
+LEFT JOIN devices ON devices.click_id = clicks.id
+CROSS JOIN LATERAL (VALUES
+  ('', ''),
+  ('country', clicks.country),
+  ('referrer', clicks.referrer_domain),
+  ('source', clicks.referrer_source),
+  ('channel', clicks.referrer_channel),
+  ('utm_source', clicks.utm_source),
+  ('utm_medium', clicks.utm_medium),
+  ('utm_campaign', clicks.utm_campaign),
+  ('device_type', COALESCE(devices.device_type, '')),
+  ('platform', COALESCE(devices.platform, '')),
+  ('language', COALESCE(devices.language, '')),
+  ('resolution', COALESCE(devices.resolution, '')),
+  ('timezone', COALESCE(devices.timezone, '')),
+  ('user_agent', COALESCE(devices.user_agent, ''))
+) AS dims(dimension, value)
+WHERE clicks.created_at >= @start_time::timestamp AND clicks.created_at < @end_time::timestamp
+  AND (dims.dimension = '' OR dims.value <> '')
+GROUP BY clicks.short_link_id, date_trunc('hour', clicks.created_at), dims.dimension, dims.value
+ON CONFLICT (short_link_id, bucket, dimension, value) DO UPDATE
+SET clicks = EXCLUDED.clicks,
+    unique_clicks = EXCLUDED.unique_clicks;
+-- name: RollUpDailyClicks :exec
+INSERT INTO click_daily_rollups(short_link_id, bucket, dimension, value, clicks, unique_clicks)
+SELECT
+  click_hourly_rollups.short_link_id,
+  date_trunc('day', click_hourly_rollups.bucket),
+  click_hourly_rollups.dimension,
+  click_hourly_rollups.value,
+  SUM(click_hourly_rollups.clicks),
+  SUM(click_hourly_rollups.unique_clicks)
+FROM click_hourly_rollups
+WHERE click_hourly_rollups.bucket >= date_trunc('day', @start_time::timestamp)
+  AND click_hourly_rollups.bucket < @end_time::timestamp
+GROUP BY click_hourly_rollups.short_link_id, date_trunc('day', click_hourly_rollups.bucket),
+  click_hourly_rollups.dimension, click_hourly_rollups.value
+ON CONFLICT (short_link_id, bucket, dimension, value) DO UPDATE
+SET clicks = EXCLUDED.clicks,
+    unique_clicks = EXCLUDED.unique_clicks;
+-- name: DailyRollupsByShortLinkId :many
+SELECT * FROM click_daily_rollups
+WHERE short_link_id = $1 AND bucket < $2;
+-- name: DailyRollupsByTag :many
+SELECT click_daily_rollups.* FROM click_daily_rollups
+JOIN short_link_tags ON short_link_tags.short_link_id = click_daily_rollups.short_link_id
+WHERE short_link_tags.tag_id = $1 AND click_daily_rollups.bucket < $2;
+-- name: DailyRollupsByFolder :many
+WITH RECURSIVE folder_tree AS (
+  SELECT folders.id FROM folders
+  WHERE folders.id = $1
+  UNION ALL
+  SELECT folders.id FROM folders
+  JOIN folder_tree ON folders.parent_id = folder_tree.id
+)
+SELECT click_daily_rollups.* FROM click_daily_rollups
+JOIN short_links ON short_links.id = click_daily_rollups.short_link_id
+WHERE short_links.folder_id IN (SELECT folder_tree.id FROM folder_tree)
+  AND click_daily_rollups.bucket < $2;
+-- name: DailyRollupsByCampaign :many
+SELECT click_daily_rollups.* FROM click_daily_rollups
+JOIN short_links ON short_links.id = click_daily_rollups.short_link_id
+WHERE short_links.campaign_id = $1 AND click_daily_rollups.bucket < $2;
+-- name: AdjustHourlyRollup :exec
+INSERT INTO click_hourly_rollups(short_link_id, bucket, dimension, value, clicks, unique_clicks)
+VALUES (@short_link_id, date_trunc('hour', @created_at::timestamp), @dimension, @value, @clicks::bigint, @unique_clicks::bigint)
+ON CONFLICT (short_link_id, bucket, dimension, value) DO UPDATE
+SET clicks = click_hourly_rollups.clicks + EXCLUDED.clicks,
+    unique_clicks = click_hourly_rollups.unique_clicks + EXCLUDED.unique_clicks;
+-- name: AdjustDailyRollup :exec
+INSERT INTO click_daily_rollups(short_link_id, bucket, dimension, value, clicks, unique_clicks)
+VALUES (@short_link_id, date_trunc('day', @created_at::timestamp), @dimension, @value, @clicks::bigint, @unique_clicks::bigint)
+ON CONFLICT (short_link_id, bucket, dimension, value) DO UPDATE
+SET clicks = click_daily_rollups.clicks + EXCLUDED.clicks,
+    unique_clicks = click_daily_rollups.unique_clicks + EXCLUDED.unique_clicks;
+-- name: DeleteEmptyRollups :exec
+WITH hourly AS (
+  DELETE FROM click_hourly_rollups WHERE click_hourly_rollups.clicks <= 0
+)
+DELETE FROM click_daily_rollups WHERE click_daily_rollups.clicks <= 0;
@@ -5,7 +5,7 @@ WHERE short_link_id = $1;
 SELECT * FROM clicks
 WHERE id = $1;
 -- name: CreateClick :one
-INSERT INTO clicks(id,short_link_id,ip_address,country,referrer,is_unique,utm_source,utm_medium,utm_campaign,created_at)
+INSERT INTO clicks(id,short_link_id,ip_address,country,referrer,is_unique,utm_source,utm_medium,utm_campaign,created_at,referrer_domain,referrer_source,referrer_channel)
 VALUES (
     gen_random_uuid(),
     $1,
@@ -16,7 +16,10 @@ VALUES (
     $6,
     $7,
     $8,
-    NOW()
+    NOW(),
+    $9,
+    $10,
+    $11
 )RETURNING id;
 -- name: CountTotalClickByShortLinkId :one
 SELECT COUNT(id) FROM clicks
@@ -32,4 +35,95 @@ SELECT
   devices.resolution, devices.timezone, devices.user_agent
 FROM clicks
 JOIN devices ON clicks.id = devices.click_id
-WHERE clicks.short_link_id = $1;
\ No newline at end of file
+WHERE clicks.short_link_id = $1 AND clicks.created_at >= $2;
+-- name: AnalyticsRetrievalByTag :many
+SELECT
+  clicks.*,
+  devices.device_type, devices.platform, devices.language,
+  devices.resolution, devices.timezone, devices.user_agent
+FROM clicks
+JOIN devices ON clicks.id = devices.click_id
+JOIN short_link_tags ON short_link_tags.short_link_id = clicks.short_link_id
+WHERE short_link_tags.tag_id = $1 AND clicks.created_at >= $2;
+-- name: AnalyticsRetrievalByFolder :many
+WITH RECURSIVE folder_tree AS (
+  SELECT folders.id FROM folders
+  WHERE folders.id = $1
+  UNION ALL
+  SELECT folders.id FROM folders
+  JOIN folder_tree ON folders.parent_id = folder_tree.id
+)
+SELECT
+  clicks.*,
+  devices.device_type, devices.platform, devices.language,
+  devices.resolution, devices.timezone, devices.user_agent
+FROM clicks
+JOIN devices ON clicks.id = devices.click_id
+JOIN short_links ON short_links.id = clicks.short_link_id
+WHERE short_links.folder_id IN (SELECT folder_tree.id FROM folder_tree)
+  AND clicks.created_at >= $2;
+-- name: AnalyticsRetrievalByCampaign :many
+SELECT
+  clicks.*,
+  devices.device_type, devices.platform, devices.language,
+  devices.resolution, devices.timezone, devices.user_agent
+FROM clicks
+JOIN devices ON clicks.id = devices.click_id
+JOIN short_links ON short_links.id = clicks.short_link_id
+WHERE short_links.campaign_id = $1 AND clicks.created_at >= $2;
+-- name: AnonymizeExpiredClicks :execrows
+WITH expired AS (
+  SELECT clicks.id FROM clicks
+  JOIN short_links ON short_links.id = clicks.short_link_id
+  JOIN users ON users.id = short_links.user_id
+  WHERE NOT clicks.anonymized
+    AND clicks.created_at < (SELECT click_rollup_state.rolled_up_to FROM click_rollup_state)
+    AND clicks.created_at < LOCALTIMESTAMP - make_interval(days => COALESCE(users.click_retention_days, @default_days::int))
+  LIMIT @batch_size::int
+), cleared AS (
+  UPDATE devices SET user_agent = ''
+  FROM expired
+  WHERE devices.click_id = expired.id
+)
+UPDATE clicks
+SET
+  ip_address = CASE
+    WHEN clicks.ip_address ~ '^[0-9]+(\.[0-9]+){3}$' THEN regexp_replace(clicks.ip_address, '[0-9]+$', '0')
+    WHEN clicks.ip_address ~ '^[0-9a-fA-F]*:[0-9a-fA-F:]*:[0-9a-fA-F:]*$' THEN host(set_masklen(clicks.ip_address::inet, 48)::cidr)
+    ELSE ''
+  END,
+  anonymized = TRUE
+FROM expired
+WHERE clicks.id = expired.id;
+-- name: DeleteExpiredClicks :execrows
+DELETE FROM clicks
+WHERE clicks.id IN (
+  SELECT expired.id FROM clicks AS expired
+  JOIN short_links ON short_links.id = expired.short_link_id
+  JOIN users ON users.id = short_links.user_id
+  WHERE expired.created_at < (SELECT click_rollup_state.rolled_up_to FROM click_rollup_state)
+    AND expired.created_at < LOCALTIMESTAMP - make_interval(days => COALESCE(users.click_retention_days, @default_days::int))
+  LIMIT @batch_size::int
+);
+-- name: ExportClicksByUserId :many
+SELECT
+  clicks.id, short_links.slug, clicks.created_at, clicks.ip_address, clicks.country, clicks.referrer,
+  clicks.is_unique, clicks.utm_source, clicks.utm_medium, clicks.utm_campaign,
+  COALESCE(devices.device_type, '')::text AS device_type,
+  COALESCE(devices.platform, '')::text AS platform,
+  COALESCE(devices.language, '')::text AS language,
+  COALESCE(devices.resolution, '')::text AS resolution,
+  COALESCE(devices.timezone, '')::text AS timezone,
+  COALESCE(devices.user_agent, '')::text AS user_agent
+FROM clicks
+LEFT JOIN devices ON clicks.id = devices.click_id
+JOIN short_links ON short_links.id = clicks.short_link_id
+WHERE short_links.user_id = @user_id
+  AND (sqlc.narg('after_id')::uuid IS NULL
+    OR (clicks.created_at, clicks.id) > (sqlc.narg('after_time')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY clicks.created_at, clicks.id
+LIMIT @batch_size::int;
+-- name: NotifyClick :exec
+SELECT pg_notify('link_clicks', @payload::text);
+-- name: NotifyLiveWatch :exec
+SELECT pg_notify('link_clicks_watch', @payload::text);
@@ -0,0 +1,21 @@
+-- name: CreateEmailToken :exec
+INSERT INTO email_tokens(id,user_id,token_hash,purpose,new_email,expires_at,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    NOW()
+);
+-- name: RetrieveValidEmailToken :one
+SELECT * FROM email_tokens
+WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW();
+-- name: MarkEmailTokenUsed :execrows
+UPDATE email_tokens
+SET used_at = NOW()
+WHERE id = $1 AND used_at IS NULL;
+-- name: DeleteUnusedEmailTokens :exec
+DELETE FROM email_tokens
+WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- name: UpsertFallbackPage :one
+INSERT INTO fallback_pages(user_id,kind,action,url,template,status_code,title,message,created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    NOW()
+)
+ON CONFLICT (user_id, kind) DO UPDATE
+SET action = EXCLUDED.action, url = EXCLUDED.url, template = EXCLUDED.template, status_code = EXCLUDED.status_code,
+  title = EXCLUDED.title, message = EXCLUDED.message, updated_at = NOW()
+RETURNING *;
+-- name: ListFallbackPagesByUserId :many
+SELECT * FROM fallback_pages
+WHERE user_id = $1
+ORDER BY kind;
+-- name: GetFallbackPage :one
+SELECT * FROM fallback_pages
+WHERE user_id = $1 AND kind = $2;
+-- name: DeleteFallbackPage :execrows
+DELETE FROM fallback_pages
+WHERE user_id = $1 AND kind = $2;
\ No newline at end of file
@@ -0,0 +1,36 @@
+-- name: CreateFolder :one
+INSERT INTO folders(id,user_id,parent_id,name,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW()
+) RETURNING *;
+-- name: ListFoldersByUserId :many
+SELECT folders.*, (
+  SELECT COUNT(short_links.id) FROM short_links
+  WHERE short_links.folder_id = folders.id AND short_links.deleted_at IS NULL
+)::BIGINT AS link_count
+FROM folders
+WHERE folders.user_id = $1
+ORDER BY folders.name;
+-- name: RetrieveFolderByUserIdANDId :one
+SELECT * FROM folders
+WHERE user_id = $1 AND id = $2;
+-- name: UpdateFolder :exec
+UPDATE folders
+SET name = $3, parent_id = $4, updated_at = NOW()
+WHERE user_id = $1 AND id = $2;
+-- name: DeleteFolderByUserIdANDId :execrows
+DELETE FROM folders
+WHERE user_id = $1 AND id = $2;
+-- name: FolderSubtreeContains :one
+WITH RECURSIVE subtree AS (
+  SELECT folders.id FROM folders
+  WHERE folders.id = @root_id::uuid
+  UNION ALL
+  SELECT folders.id FROM folders
+  JOIN subtree ON folders.parent_id = subtree.id
+)
+SELECT EXISTS (SELECT 1 FROM subtree WHERE subtree.id = @folder_id::uuid);
\ No newline at end of file
@@ -0,0 +1,44 @@
+-- name: SeedLinkHealth :exec
+INSERT INTO link_health(short_link_id,next_check_at)
+SELECT short_links.id, NOW() FROM short_links
+WHERE short_links.is_active AND short_links.deleted_at IS NULL AND NOT EXISTS (
+  SELECT 1 FROM link_health WHERE link_health.short_link_id = short_links.id
+)
+ON CONFLICT (short_link_id) DO NOTHING;
+-- name: ClaimDueLinkHealthChecks :many
+UPDATE link_health
+SET next_check_at = NOW() + make_interval(secs => @lease_seconds::int)
+WHERE link_health.short_link_id IN (
+  SELECT due.short_link_id FROM link_health due
+  JOIN short_links ON short_links.id = due.short_link_id
+  WHERE short_links.is_active AND short_links.quarantined_at IS NULL AND short_links.deleted_at IS NULL
+    AND due.next_check_at <= NOW()
+  ORDER BY due.next_check_at
+  LIMIT @batch_size::int
+  FOR UPDATE OF due SKIP LOCKED
+)
+RETURNING link_health.*;
+-- name: RecordLinkHealthCheck :exec
+UPDATE link_health
+SET status = @status, status_code = @status_code, latency_ms = @latency_ms, final_url = @final_url, error = @error,
+  tls_error = @tls_error, consecutive_failures = @consecutive_failures, checked_at = NOW(),
+  next_check_at = NOW() + make_interval(secs => @interval_seconds::int),
+  last_healthy_at = CASE WHEN @status = 'healthy' THEN NOW() ELSE last_healthy_at END
+WHERE short_link_id = @short_link_id;
+-- name: GetLinkHealth :one
+SELECT * FROM link_health
+WHERE short_link_id = $1;
+-- name: ScheduleLinkHealthCheck :exec
+INSERT INTO link_health(short_link_id,next_check_at)
+VALUES($1, NOW())
+ON CONFLICT (short_link_id) DO UPDATE
+SET next_check_at = NOW();
+-- name: UpdateShortLinkHealthAction :execrows
+UPDATE short_links
+SET health_action = $3, health_fallback_url = $4, updated_at = NOW()
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NULL;
+-- name: DisableUnhealthyShortLink :one
+UPDATE short_links
+SET is_active = FALSE, updated_at = NOW()
+WHERE id = $1 AND is_active
+RETURNING *;
\ No newline at end of file
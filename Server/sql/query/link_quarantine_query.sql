@@ -0,0 +1,25 @@
+-- name: QuarantineShortLink :one
+UPDATE short_links
+SET quarantined_at = NOW(), quarantine_reason = $2, quarantined_by = $3
+WHERE id = $1 AND quarantined_at IS NULL
+RETURNING *;
+-- name: ReleaseShortLinkQuarantine :one
+UPDATE short_links
+SET quarantined_at = NULL, quarantine_reason = '', quarantined_by = ''
+WHERE id = $1 AND quarantined_by = $2
+RETURNING *;
+-- name: ListShortLinksForThreatScan :many
+SELECT * FROM short_links
+WHERE id > @after_id AND deleted_at IS NULL
+ORDER BY id
+LIMIT @batch_size::int;
+-- name: ListShortLinkFallbackUrls :many
+SELECT link_schedules.fallback_url::text AS url FROM link_schedules
+WHERE link_schedules.short_link_id = @short_link_id AND link_schedules.fallback_url <> ''
+UNION
+SELECT fallback_pages.url FROM fallback_pages
+JOIN short_links ON short_links.user_id = fallback_pages.user_id
+WHERE short_links.id = @short_link_id AND fallback_pages.action = 'redirect' AND fallback_pages.kind <> 'blocked' AND fallback_pages.url <> ''
+UNION
+SELECT short_links.health_fallback_url FROM short_links
+WHERE short_links.id = @short_link_id AND short_links.health_action = 'fallback' AND short_links.health_fallback_url <> '';
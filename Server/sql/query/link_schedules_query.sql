@@ -0,0 +1,26 @@
+-- name: UpsertLinkSchedule :one
+INSERT INTO link_schedules(short_link_id,timezone,activate_at,deactivate_at,windows,fallback_url,created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW()
+)
+ON CONFLICT (short_link_id) DO UPDATE
+SET timezone = EXCLUDED.timezone, activate_at = EXCLUDED.activate_at, deactivate_at = EXCLUDED.deactivate_at,
+  windows = EXCLUDED.windows, fallback_url = EXCLUDED.fallback_url, expiry_notified = FALSE, updated_at = NOW()
+RETURNING *;
+-- name: GetLinkSchedule :one
+SELECT * FROM link_schedules
+WHERE short_link_id = $1;
+-- name: DeleteLinkSchedule :execrows
+DELETE FROM link_schedules
+WHERE short_link_id = $1;
+-- name: ClaimExpiredLinkSchedules :many
+UPDATE link_schedules
+SET expiry_notified = TRUE
+WHERE deactivate_at <= @now::timestamp AND NOT expiry_notified
+RETURNING short_link_id;
\ No newline at end of file
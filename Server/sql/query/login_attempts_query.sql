@@ -0,0 +1,36 @@
+-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts(id,user_id,email,ip_address,user_agent,success,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    NOW()
+);
+-- name: CountFailedLoginAttemptsByEmail :one
+SELECT COUNT(id) AS failures,
+    COALESCE(MIN(created_at), $2)::timestamp AS first_failure_at,
+    COALESCE(MAX(created_at), $2)::timestamp AS last_failure_at
+FROM login_attempts
+WHERE email = $1 AND success = FALSE AND created_at > $2
+AND created_at > COALESCE((
+    SELECT MAX(la.created_at) FROM login_attempts la
+    WHERE la.email = $1 AND la.success = TRUE
+), '-infinity'::timestamp);
+-- name: CountFailedLoginAttemptsByIP :one
+SELECT COUNT(id) AS failures,
+    COALESCE(MIN(created_at), $2)::timestamp AS first_failure_at,
+    COALESCE(MAX(created_at), $2)::timestamp AS last_failure_at
+FROM login_attempts
+WHERE ip_address = $1 AND success = FALSE AND created_at > $2;
+-- name: RetrieveLoginAttemptsByUserId :many
+SELECT * FROM login_attempts
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+-- name: ExportLoginAttemptsByUserId :many
+SELECT * FROM login_attempts
+WHERE user_id = $1
+ORDER BY created_at;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- name: CreateRecoveryCode :exec
+INSERT INTO mfa_recovery_codes(id,user_id,code_hash,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW()
+);
+-- name: UseRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+-- name: DeleteRecoveryCodesByUserId :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- name: CreateOidcState :exec
+INSERT INTO oidc_states(id,state_hash,provider,nonce,code_verifier,expires_at,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    NOW()
+);
+-- name: ConsumeOidcState :one
+DELETE FROM oidc_states
+WHERE state_hash = $1 AND provider = $2 AND expires_at > NOW()
+RETURNING *;
+-- name: DeleteExpiredOidcStates :exec
+DELETE FROM oidc_states
+WHERE expires_at <= NOW();
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- name: QuarantineUserSlugs :exec
+INSERT INTO quarantined_slugs(slug, released_at, created_at)
+SELECT short_links.slug, NOW() + make_interval(days => @quarantine_days::int), NOW()
+FROM short_links
+WHERE short_links.user_id = @user_id
+ON CONFLICT (slug) DO UPDATE
+SET released_at = EXCLUDED.released_at;
+-- name: QuarantineSlug :exec
+INSERT INTO quarantined_slugs(slug, released_at, created_at)
+VALUES(@slug, NOW() + make_interval(days => @quarantine_days::int), NOW())
+ON CONFLICT (slug) DO UPDATE
+SET released_at = EXCLUDED.released_at;
+-- name: IsSlugQuarantined :one
+SELECT EXISTS(
+  SELECT 1 FROM quarantined_slugs
+  WHERE slug = $1 AND released_at > NOW()
+);
+-- name: DeleteReleasedQuarantinedSlugs :execrows
+DELETE FROM quarantined_slugs
+WHERE released_at <= NOW();
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- name: ListReferrerSources :many
+SELECT * FROM referrer_sources;
+-- name: ListClickReferrersAfter :many
+SELECT
+  clicks.id, clicks.short_link_id, clicks.created_at, clicks.is_unique, clicks.referrer, clicks.utm_medium,
+  clicks.referrer_domain, clicks.referrer_source, clicks.referrer_channel
+FROM clicks
+WHERE clicks.id > @after_id::uuid
+ORDER BY clicks.id
+LIMIT @batch_size::int;
+-- name: UpdateClickReferrer :exec
+UPDATE clicks
+SET referrer_domain = $2, referrer_source = $3, referrer_channel = $4
+WHERE id = $1;
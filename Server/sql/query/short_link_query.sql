@@ -3,18 +3,21 @@ SELECT * FROM short_links
 WHERE id = $1;
 -- name: RetrieveShortLinkByUserId :many
 SELECT * FROM short_links
-WHERE user_id = $1;
+WHERE user_id = $1 AND deleted_at IS NULL;
 -- name: RetrieveShortLinkByUserIdANDId :one
 SELECT * FROM short_links
-WHERE user_id = $1 AND id = $2;
+WHERE user_id = $1 AND id = $2 AND deleted_at IS NULL;
 -- name: RetrieveShortLinkBySlug :one
 SELECT * FROM short_links
+WHERE slug = $1 AND deleted_at IS NULL;
+-- name: RetrieveShortLinkBySlugWithTrashed :one
+SELECT * FROM short_links
 WHERE slug = $1;
 -- name: RetrieveShortLinkBySlugNUserId :one
 SELECT * FROM short_links
-WHERE slug = $1 AND user_id = $2;
--- name: CreateShortLink :exec
-INSERT INTO short_links(id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign,is_active,created_at)
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NULL;
+-- name: CreateShortLink :one
+INSERT INTO short_links(id, user_id, slug, original_url, utm_source, utm_medium, utm_campaign,is_active,created_at,title,campaign_id,utm_term,utm_content,extra_params,utm_policy,pass_query,privacy_mode)
 VALUES(
     gen_random_uuid(),
     $1,
@@ -24,23 +27,194 @@ VALUES(
     $5,
     $6,
     TRUE,
-    NOW()
+    NOW(),
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    $13,
+    $14
 ) RETURNING *;
--- name: ToggleShortLink :exec
+-- name: ToggleShortLink :one
 UPDATE short_links
 SET
   is_active = NOT is_active, -- Toggles the boolean value
   updated_at = NOW()         -- Updates the timestamp to the current time
 WHERE
-  slug = $1 AND user_id = $2; 
--- name: UpdateShortLinkSlug :exec
+  slug = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING *;
+-- name: UpdateShortLinkSlug :one
 UPDATE short_links
 SET slug = $3,updated_at = NOW()
-WHERE slug = $1 AND user_id = $2;
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING *;
 -- name: UpdateShortLinkUTM :exec
 UPDATE short_links
-SET utm_source = $2, utm_medium = $3, utm_campaign = $4,updated_at = NOW()
-WHERE slug = $1 AND user_id = $5;
--- name: DeleteShortLinkBySlugNUserId :exec
+SET utm_source = $2, utm_medium = $3, utm_campaign = $4,updated_at = NOW(),
+utm_term = $6, utm_content = $7, extra_params = $8, utm_policy = $9, pass_query = $10
+WHERE slug = $1 AND user_id = $5 AND deleted_at IS NULL;
+-- name: UpdateShortLinkPrivacyMode :execrows
+UPDATE short_links
+SET privacy_mode = $3, updated_at = NOW()
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NULL;
+-- name: RetrieveShortLinkBySlugNUserIdWithTrashed :one
+SELECT * FROM short_links
+WHERE slug = $1 AND user_id = $2;
+-- name: TrashShortLinkBySlugNUserId :one
+UPDATE short_links
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING *;
+-- name: RestoreShortLinkBySlugNUserId :one
+UPDATE short_links
+SET deleted_at = NULL, updated_at = NOW()
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING *;
+-- name: DeleteTrashedShortLinkBySlugNUserId :one
+DELETE FROM short_links
+WHERE slug = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING *;
+-- name: ListTrashedShortLinksByUserId :many
+SELECT * FROM short_links
+WHERE user_id = @user_id AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+LIMIT @page_limit::int OFFSET @page_offset::int;
+-- name: PurgeTrashedShortLinks :many
 DELETE FROM short_links
-WHERE slug = $1 AND user_id = $2;
\ No newline at end of file
+WHERE id IN (
+  SELECT id FROM short_links
+  WHERE deleted_at < NOW() - make_interval(days => @trash_days::int)
+  LIMIT @batch_size::int
+)
+RETURNING *;
+-- name: ListShortLinksWithStats :many
+WITH RECURSIVE folder_tree AS (
+  SELECT folders.id FROM folders
+  WHERE folders.id = sqlc.narg('folder_id')::uuid AND folders.user_id = @user_id
+  UNION ALL
+  SELECT folders.id FROM folders
+  JOIN folder_tree ON folders.parent_id = folder_tree.id
+), matches AS (
+  -- Clicks are only counted here when sorting by them; the rest is joined after the page is cut
+  SELECT short_links.id, short_links.created_at, click_totals.total_clicks
+  FROM short_links
+  CROSS JOIN click_rollup_state
+  LEFT JOIN link_health ON link_health.short_link_id = short_links.id
+  CROSS JOIN LATERAL (
+    SELECT COALESCE(SUM(counts.total_clicks), 0)::BIGINT AS total_clicks
+    FROM (
+      SELECT click_hourly_rollups.clicks AS total_clicks
+      FROM click_hourly_rollups
+      WHERE click_hourly_rollups.short_link_id = short_links.id AND click_hourly_rollups.dimension = ''
+        AND click_hourly_rollups.bucket < click_rollup_state.rolled_up_to AND @sort::text IN ('clicks_asc', 'clicks_desc')
+      UNION ALL
+      SELECT 1
+      FROM clicks
+      WHERE clicks.short_link_id = short_links.id AND clicks.created_at >= click_rollup_state.rolled_up_to
+        AND @sort::text IN ('clicks_asc', 'clicks_desc')
+    ) counts
+  ) click_totals
+  WHERE short_links.user_id = @user_id AND short_links.deleted_at IS NULL
+    AND (sqlc.narg('search')::text IS NULL
+      OR to_tsvector('simple', short_links.slug || ' ' || short_links.original_url || ' ' || short_links.title) @@ websearch_to_tsquery('simple', sqlc.narg('search')::text)
+      OR short_links.slug ILIKE replace(replace(replace(sqlc.narg('search')::text, '\', '\\'), '%', '\%'), '_', '\_') || '%')
+    AND (sqlc.narg('is_active')::boolean IS NULL OR short_links.is_active = sqlc.narg('is_active')::boolean)
+    AND (sqlc.narg('campaign')::text IS NULL OR short_links.utm_campaign = sqlc.narg('campaign')::text)
+    AND (sqlc.narg('created_after')::timestamp IS NULL OR short_links.created_at >= sqlc.narg('created_after')::timestamp)
+    AND (sqlc.narg('created_before')::timestamp IS NULL OR short_links.created_at < sqlc.narg('created_before')::timestamp)
+    AND (sqlc.narg('tag_id')::uuid IS NULL OR EXISTS (
+      SELECT 1 FROM short_link_tags
+      WHERE short_link_tags.short_link_id = short_links.id AND short_link_tags.tag_id = sqlc.narg('tag_id')::uuid))
+    AND (sqlc.narg('folder_id')::uuid IS NULL OR short_links.folder_id IN (SELECT folder_tree.id FROM folder_tree))
+    AND (sqlc.narg('campaign_id')::uuid IS NULL OR short_links.campaign_id = sqlc.narg('campaign_id')::uuid)
+    AND (sqlc.narg('health')::text IS NULL OR COALESCE(link_health.status, 'unknown') = sqlc.narg('health')::text)
+), page AS (
+  SELECT matches.id FROM matches
+  WHERE sqlc.narg('cursor_id')::uuid IS NULL OR CASE @sort::text
+      WHEN 'created_asc' THEN (matches.created_at, matches.id) > (sqlc.narg('cursor_time')::timestamp, sqlc.narg('cursor_id')::uuid)
+      WHEN 'clicks_asc' THEN (matches.total_clicks, matches.id) > (sqlc.narg('cursor_clicks')::bigint, sqlc.narg('cursor_id')::uuid)
+      WHEN 'clicks_desc' THEN (matches.total_clicks, matches.id) < (sqlc.narg('cursor_clicks')::bigint, sqlc.narg('cursor_id')::uuid)
+      WHEN 'created_desc' THEN (matches.created_at, matches.id) < (sqlc.narg('cursor_time')::timestamp, sqlc.narg('cursor_id')::uuid)
+    END
+  ORDER BY
+    CASE WHEN @sort::text = 'created_asc' THEN matches.created_at END ASC,
+    CASE WHEN @sort::text = 'created_desc' THEN matches.created_at END DESC,
+    CASE WHEN @sort::text = 'clicks_asc' THEN matches.total_clicks END ASC,
+    CASE WHEN @sort::text = 'clicks_desc' THEN matches.total_clicks END DESC,
+    CASE WHEN @sort::text IN ('created_asc', 'clicks_asc') THEN matches.id END ASC,
+    CASE WHEN @sort::text IN ('created_desc', 'clicks_desc') THEN matches.id END DESC
+  LIMIT @page_limit::int
+), links AS (
+  SELECT
+    short_links.*,
+    stats.total_clicks::BIGINT AS total_clicks,
+    stats.unique_clicks::BIGINT AS unique_clicks,
+    COALESCE(link_tags.tags, '{}')::TEXT[] AS tags,
+    COALESCE(link_health.status, 'unknown')::TEXT AS health_status,
+    link_health.status_code AS health_status_code,
+    link_health.checked_at AS health_checked_at
+  FROM page
+  JOIN short_links ON short_links.id = page.id
+  CROSS JOIN click_rollup_state
+  LEFT JOIN link_health ON link_health.short_link_id = short_links.id
+  CROSS JOIN LATERAL (
+    -- Raw clicks behind the watermark may be gone after retention, so they come from the rollups
+    SELECT COALESCE(SUM(counts.total_clicks), 0) AS total_clicks, COALESCE(SUM(counts.unique_clicks), 0) AS unique_clicks
+    FROM (
+      SELECT click_hourly_rollups.clicks AS total_clicks, click_hourly_rollups.unique_clicks
+      FROM click_hourly_rollups
+      WHERE click_hourly_rollups.short_link_id = short_links.id AND click_hourly_rollups.dimension = ''
+        AND click_hourly_rollups.bucket < click_rollup_state.rolled_up_to
+      UNION ALL
+      SELECT 1, CASE WHEN clicks.is_unique THEN 1 ELSE 0 END
+      FROM clicks
+      WHERE clicks.short_link_id = short_links.id AND clicks.created_at >= click_rollup_state.rolled_up_to
+    ) counts
+  ) stats
+  CROSS JOIN LATERAL (
+    SELECT array_agg(tags.name ORDER BY tags.name) AS tags
+    FROM short_link_tags
+    JOIN tags ON tags.id = short_link_tags.tag_id
+    WHERE short_link_tags.short_link_id = short_links.id
+  ) link_tags
+)
+SELECT * FROM links
+ORDER BY
+  CASE WHEN @sort::text = 'created_asc' THEN links.created_at END ASC,
+  CASE WHEN @sort::text = 'created_desc' THEN links.created_at END DESC,
+  CASE WHEN @sort::text = 'clicks_asc' THEN links.total_clicks END ASC,
+  CASE WHEN @sort::text = 'clicks_desc' THEN links.total_clicks END DESC,
+  CASE WHEN @sort::text IN ('created_asc', 'clicks_asc') THEN links.id END ASC,
+  CASE WHEN @sort::text IN ('created_desc', 'clicks_desc') THEN links.id END DESC;
+-- name: MoveShortLinksToFolder :many
+UPDATE short_links
+SET folder_id = sqlc.narg('folder_id'), updated_at = NOW()
+WHERE user_id = @user_id AND slug = ANY(@slugs::text[]) AND deleted_at IS NULL
+RETURNING *;
+-- name: AttachShortLinksToCampaign :many
+UPDATE short_links
+SET campaign_id = sqlc.narg('campaign_id'),
+  utm_source = CASE WHEN short_links.utm_source = '' THEN @utm_source::text ELSE short_links.utm_source END,
+  utm_medium = CASE WHEN short_links.utm_medium = '' THEN @utm_medium::text ELSE short_links.utm_medium END,
+  utm_campaign = CASE WHEN short_links.utm_campaign = '' THEN @utm_campaign::text ELSE short_links.utm_campaign END,
+  updated_at = NOW()
+WHERE user_id = @user_id AND slug = ANY(@slugs::text[]) AND deleted_at IS NULL
+RETURNING *;
+-- name: CountShortLinksByCampaignId :one
+SELECT COUNT(id) FROM short_links
+WHERE campaign_id = $1 AND deleted_at IS NULL;
+-- name: ExportShortLinksByUserId :many
+SELECT
+  short_links.*,
+  COALESCE(link_tags.tags, '{}')::TEXT[] AS tags
+FROM short_links
+CROSS JOIN LATERAL (
+  SELECT array_agg(tags.name ORDER BY tags.name) AS tags
+  FROM short_link_tags
+  JOIN tags ON tags.id = short_link_tags.tag_id
+  WHERE short_link_tags.short_link_id = short_links.id
+) link_tags
+WHERE short_links.user_id = $1
+ORDER BY short_links.created_at;
\ No newline at end of file
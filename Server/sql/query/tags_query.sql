@@ -0,0 +1,47 @@
+-- name: CreateTag :one
+INSERT INTO tags(id,user_id,name,color,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW()
+) RETURNING *;
+-- name: ListTagsByUserId :many
+SELECT tags.*, (
+  SELECT COUNT(short_link_tags.short_link_id) FROM short_link_tags
+  JOIN short_links ON short_links.id = short_link_tags.short_link_id
+  WHERE short_link_tags.tag_id = tags.id AND short_links.deleted_at IS NULL
+)::BIGINT AS link_count
+FROM tags
+WHERE tags.user_id = $1
+ORDER BY tags.name;
+-- name: RetrieveTagByUserIdANDId :one
+SELECT * FROM tags
+WHERE user_id = $1 AND id = $2;
+-- name: UpdateTag :execrows
+UPDATE tags
+SET name = $3, color = $4
+WHERE user_id = $1 AND id = $2;
+-- name: DeleteTagByUserIdANDId :execrows
+DELETE FROM tags
+WHERE user_id = $1 AND id = $2;
+-- name: CountTagsByUserIdANDIds :one
+SELECT COUNT(id) FROM tags
+WHERE user_id = @user_id AND id = ANY(@ids::uuid[]);
+-- name: AddTagsToShortLinks :many
+INSERT INTO short_link_tags(short_link_id, tag_id)
+SELECT short_links.id, tags.id
+FROM short_links
+CROSS JOIN tags
+WHERE short_links.user_id = @user_id AND short_links.slug = ANY(@slugs::text[]) AND short_links.deleted_at IS NULL
+  AND tags.user_id = @user_id AND tags.id = ANY(@tag_ids::uuid[])
+ON CONFLICT DO NOTHING
+RETURNING short_link_id;
+-- name: RemoveTagsFromShortLinks :many
+DELETE FROM short_link_tags
+USING short_links
+WHERE short_link_tags.short_link_id = short_links.id
+  AND short_links.user_id = @user_id AND short_links.slug = ANY(@slugs::text[])
+  AND short_link_tags.tag_id = ANY(@tag_ids::uuid[])
+RETURNING short_link_tags.short_link_id;
\ No newline at end of file
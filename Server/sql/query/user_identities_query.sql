@@ -0,0 +1,17 @@
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities(id,user_id,provider,subject,email,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+);
+-- name: RetrieveUserIdentity :one
+SELECT * FROM user_identities
+WHERE provider = $1 AND subject = $2;
+-- name: RetrieveUserIdentitiesByUserId :many
+SELECT * FROM user_identities
+WHERE user_id = $1
+ORDER BY created_at;
\ No newline at end of file
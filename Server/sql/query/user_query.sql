@@ -23,9 +23,55 @@ SET name = $2, updated_at = NOW()
 WHERE id = $1;
 -- name: UpdateUserEmail :exec
 UPDATE users
-SET email = $2, updated_at = NOW()
+SET email = $2, email_verified = TRUE, updated_at = NOW()
 WHERE id = $1;
 -- name: UpdateUserPassword :exec
 UPDATE users
-SET password = $2, updated_at = NOW()
+SET password = $2, tokens_valid_after = FLOOR(EXTRACT(EPOCH FROM NOW()))::BIGINT, updated_at = NOW()
+WHERE id = $1;
+-- name: VerifyUserEmail :exec
+UPDATE users
+SET email_verified = TRUE, updated_at = NOW()
+WHERE id = $1;
+-- name: SetUserMfaSecret :exec
+UPDATE users
+SET mfa_secret = $2, mfa_enabled = FALSE, mfa_last_used_step = 0, updated_at = NOW()
+WHERE id = $1;
+-- name: EnableUserMfa :exec
+UPDATE users
+SET mfa_enabled = TRUE, updated_at = NOW()
+WHERE id = $1;
+-- name: DisableUserMfa :exec
+UPDATE users
+SET mfa_enabled = FALSE, mfa_secret = '', mfa_last_used_step = 0, updated_at = NOW()
+WHERE id = $1;
+-- name: UpdateUserMfaLastUsedStep :execrows
+UPDATE users
+SET mfa_last_used_step = $2
+WHERE id = $1 AND mfa_last_used_step < $2;
+-- name: SetUserClickRetention :execrows
+UPDATE users
+SET click_retention_days = $2, updated_at = NOW()
+WHERE id = $1;
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = NOW() + make_interval(days => @grace_days::int), updated_at = NOW()
+WHERE id = @id
+RETURNING deletion_scheduled_at;
+-- name: CancelUserDeletion :exec
+UPDATE users
+SET deletion_scheduled_at = NULL, updated_at = NOW()
+WHERE id = $1;
+-- name: ListUsersDueForDeletion :many
+SELECT id FROM users
+WHERE deletion_scheduled_at <= NOW();
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
+-- name: UpdateUserPrivacyMode :exec
+UPDATE users
+SET privacy_mode = $2, updated_at = NOW()
+WHERE id = $1;
+-- name: GetUserPrivacyMode :one
+SELECT privacy_mode FROM users
 WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,68 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks(id,user_id,url,secret,events,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+) RETURNING *;
+-- name: ListWebhooksByUserId :many
+SELECT * FROM webhooks
+WHERE user_id = $1
+ORDER BY created_at DESC;
+-- name: RetrieveWebhookByUserIdANDId :one
+SELECT * FROM webhooks
+WHERE user_id = $1 AND id = $2;
+-- name: RetrieveWebhookById :one
+SELECT * FROM webhooks
+WHERE id = $1;
+-- name: ListWebhooksForEvent :many
+SELECT * FROM webhooks
+WHERE user_id = @user_id AND is_active AND @event::text = ANY(events);
+-- name: UpdateWebhook :execrows
+UPDATE webhooks
+SET url = $3, events = $4, is_active = $5, updated_at = NOW()
+WHERE user_id = $1 AND id = $2;
+-- name: DeleteWebhookByUserIdANDId :execrows
+DELETE FROM webhooks
+WHERE user_id = $1 AND id = $2;
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries(id,webhook_id,event,payload,next_attempt_at,created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW(),
+    NOW()
+) RETURNING *;
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = NOW() + make_interval(secs => @lease_seconds::int)
+WHERE webhook_deliveries.id IN (
+  SELECT due.id FROM webhook_deliveries due
+  WHERE due.status = 'pending' AND due.next_attempt_at <= NOW()
+  ORDER BY due.next_attempt_at
+  LIMIT @batch_size::int
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING webhook_deliveries.*;
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET status = 'succeeded', attempts = attempts + 1, response_status = $2, last_error = '', next_attempt_at = NULL, delivered_at = NOW()
+WHERE id = $1;
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = @status::text, attempts = attempts + 1, response_status = @response_status, last_error = @last_error::text,
+  next_attempt_at = sqlc.narg('next_attempt_at')::timestamp
+WHERE id = @id;
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+-- name: RetrieveWebhookDeliveryByWebhookIdANDId :one
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1 AND id = $2;
\ No newline at end of file
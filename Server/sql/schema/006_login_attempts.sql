@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE login_attempts(
+    id UUID PRIMARY KEY UNIQUE NOT NULL,
+    user_id UUID,
+    email TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    success BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX login_attempts_email_idx ON login_attempts(email, created_at);
+CREATE INDEX login_attempts_ip_idx ON login_attempts(ip_address, created_at);
+-- +goose down
+DROP TABLE login_attempts;
\ No newline at end of file
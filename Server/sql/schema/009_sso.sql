@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE user_identities(
+    id UUID PRIMARY KEY UNIQUE NOT NULL,
+    user_id UUID NOT NULL,
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (provider, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE TABLE oidc_states(
+    id UUID PRIMARY KEY UNIQUE NOT NULL,
+    state_hash TEXT UNIQUE NOT NULL,
+    provider TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+-- +goose down
+DROP TABLE oidc_states;
+DROP TABLE user_identities;
\ No newline at end of file
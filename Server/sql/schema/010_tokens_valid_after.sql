@@ -0,0 +1,4 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN tokens_valid_after BIGINT NOT NULL DEFAULT 0;
+-- +goose down
+ALTER TABLE users DROP COLUMN tokens_valid_after;
\ No newline at end of file
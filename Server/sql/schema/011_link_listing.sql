@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE short_links ADD COLUMN title TEXT NOT NULL DEFAULT '';
+CREATE INDEX clicks_short_link_id_idx ON clicks(short_link_id, created_at);
+CREATE INDEX short_links_user_id_created_at_idx ON short_links(user_id, created_at);
+CREATE INDEX short_links_search_idx ON short_links
+USING GIN (to_tsvector('simple', slug || ' ' || original_url || ' ' || title));
+-- +goose down
+DROP INDEX short_links_search_idx;
+DROP INDEX short_links_user_id_created_at_idx;
+DROP INDEX clicks_short_link_id_idx;
+ALTER TABLE short_links DROP COLUMN title;
\ No newline at end of file
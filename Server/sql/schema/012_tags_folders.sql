@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE folders(
+    id UUID PRIMARY KEY UNIQUE NOT NULL,
+    user_id UUID NOT NULL,
+    parent_id UUID,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES folders(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX folders_user_id_parent_id_name_idx
+ON folders(user_id, COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'), lower(name));
+CREATE TABLE tags(
+    id UUID PRIMARY KEY UNIQUE NOT NULL,
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    color TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX tags_user_id_name_idx ON tags(user_id, lower(name));
+CREATE TABLE short_link_tags(
+    short_link_id UUID NOT NULL,
+    tag_id UUID NOT NULL,
+    PRIMARY KEY (short_link_id, tag_id),
+    FOREIGN KEY (short_link_id) REFERENCES short_links(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+CREATE INDEX short_link_tags_tag_id_idx ON short_link_tags(tag_id);
+ALTER TABLE short_links ADD COLUMN folder_id UUID REFERENCES folders(id) ON DELETE SET NULL;
+CREATE INDEX short_links_folder_id_idx ON short_links(folder_id);
+-- +goose down
+DROP INDEX short_links_folder_id_idx;
+ALTER TABLE short_links DROP COLUMN folder_id;
+DROP TABLE short_link_tags;
+DROP TABLE tags;
+DROP TABLE folders;
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE campaigns(
+    id UUID PRIMARY KEY UNIQUE NOT NULL,
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    utm_source TEXT NOT NULL,
+    utm_medium TEXT NOT NULL,
+    utm_campaign TEXT NOT NULL,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    budget_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX campaigns_user_id_name_idx ON campaigns(user_id, lower(name));
+ALTER TABLE short_links ADD COLUMN campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL;
+CREATE INDEX short_links_campaign_id_idx ON short_links(campaign_id);
+-- +goose down
+DROP INDEX short_links_campaign_id_idx;
+ALTER TABLE short_links DROP COLUMN campaign_id;
+DROP TABLE campaigns;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE short_links ADD COLUMN utm_term TEXT NOT NULL DEFAULT '';
+ALTER TABLE short_links ADD COLUMN utm_content TEXT NOT NULL DEFAULT '';
+ALTER TABLE short_links ADD COLUMN extra_params JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE short_links ADD COLUMN utm_policy TEXT NOT NULL DEFAULT 'override';
+ALTER TABLE short_links ADD COLUMN pass_query BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose down
+ALTER TABLE short_links DROP COLUMN pass_query;
+ALTER TABLE short_links DROP COLUMN utm_policy;
+ALTER TABLE short_links DROP COLUMN extra_params;
+ALTER TABLE short_links DROP COLUMN utm_content;
+ALTER TABLE short_links DROP COLUMN utm_term;
\ No newline at end of file
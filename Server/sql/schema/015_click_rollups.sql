@@ -0,0 +1,40 @@
+-- +goose Up
+ALTER TABLE clicks ADD COLUMN referrer_domain TEXT NOT NULL DEFAULT '';
+UPDATE clicks
+SET referrer_domain = regexp_replace(
+  lower(COALESCE(substring(referrer from '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/?#]*@)?([^/:?#]+)'), '')),
+  '^www\.', ''
+);
+CREATE INDEX clicks_created_at_idx ON clicks(created_at);
+CREATE TABLE click_hourly_rollups(
+    short_link_id UUID NOT NULL,
+    bucket TIMESTAMP NOT NULL,
+    dimension TEXT NOT NULL,
+    value TEXT NOT NULL,
+    clicks BIGINT NOT NULL,
+    unique_clicks BIGINT NOT NULL,
+    PRIMARY KEY (short_link_id, bucket, dimension, value),
+    FOREIGN KEY (short_link_id) REFERENCES short_links(id) ON DELETE CASCADE
+);
+CREATE TABLE click_daily_rollups(
+    short_link_id UUID NOT NULL,
+    bucket TIMESTAMP NOT NULL,
+    dimension TEXT NOT NULL,
+    value TEXT NOT NULL,
+    clicks BIGINT NOT NULL,
+    unique_clicks BIGINT NOT NULL,
+    PRIMARY KEY (short_link_id, bucket, dimension, value),
+    FOREIGN KEY (short_link_id) REFERENCES short_links(id) ON DELETE CASCADE
+);
+CREATE TABLE click_rollup_state(
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    rolled_up_to TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+INSERT INTO click_rollup_state(id, rolled_up_to, updated_at) VALUES (TRUE, '1970-01-01', NOW());
+-- +goose down
+DROP TABLE click_rollup_state;
+DROP TABLE click_daily_rollups;
+DROP TABLE click_hourly_rollups;
+DROP INDEX clicks_created_at_idx;
+ALTER TABLE clicks DROP COLUMN referrer_domain;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE clicks ADD COLUMN anonymized BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN click_retention_days INT;
+-- +goose down
+ALTER TABLE users DROP COLUMN click_retention_days;
+ALTER TABLE clicks DROP COLUMN anonymized;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP;
+CREATE TABLE quarantined_slugs(
+    slug TEXT PRIMARY KEY NOT NULL,
+    released_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+-- +goose down
+DROP TABLE quarantined_slugs;
+ALTER TABLE users DROP COLUMN deletion_scheduled_at;
\ No newline at end of file
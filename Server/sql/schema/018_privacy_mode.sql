@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN privacy_mode TEXT NOT NULL DEFAULT 'off';
+ALTER TABLE short_links ADD COLUMN privacy_mode TEXT NOT NULL DEFAULT '';
+-- +goose down
+ALTER TABLE short_links DROP COLUMN privacy_mode;
+ALTER TABLE users DROP COLUMN privacy_mode;
\ No newline at end of file
@@ -0,0 +1,69 @@
+-- +goose Up
+CREATE TABLE referrer_sources(
+    domain TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('search', 'social', 'email', 'paid'))
+);
+INSERT INTO referrer_sources(domain, name, channel) VALUES
+  ('google.com', 'Google', 'search'),
+  ('google.co.uk', 'Google', 'search'),
+  ('google.co.in', 'Google', 'search'),
+  ('google.com.au', 'Google', 'search'),
+  ('google.ca', 'Google', 'search'),
+  ('google.de', 'Google', 'search'),
+  ('google.fr', 'Google', 'search'),
+  ('google.es', 'Google', 'search'),
+  ('google.it', 'Google', 'search'),
+  ('google.com.br', 'Google', 'search'),
+  ('google.co.jp', 'Google', 'search'),
+  ('bing.com', 'Bing', 'search'),
+  ('duckduckgo.com', 'DuckDuckGo', 'search'),
+  ('search.yahoo.com', 'Yahoo', 'search'),
+  ('yandex.ru', 'Yandex', 'search'),
+  ('yandex.com', 'Yandex', 'search'),
+  ('baidu.com', 'Baidu', 'search'),
+  ('ecosia.org', 'Ecosia', 'search'),
+  ('search.brave.com', 'Brave Search', 'search'),
+  ('startpage.com', 'Startpage', 'search'),
+  ('t.co', 'Twitter/X', 'social'),
+  ('twitter.com', 'Twitter/X', 'social'),
+  ('x.com', 'Twitter/X', 'social'),
+  ('facebook.com', 'Facebook', 'social'),
+  ('fb.me', 'Facebook', 'social'),
+  ('instagram.com', 'Instagram', 'social'),
+  ('threads.net', 'Threads', 'social'),
+  ('linkedin.com', 'LinkedIn', 'social'),
+  ('lnkd.in', 'LinkedIn', 'social'),
+  ('reddit.com', 'Reddit', 'social'),
+  ('youtube.com', 'YouTube', 'social'),
+  ('youtu.be', 'YouTube', 'social'),
+  ('pinterest.com', 'Pinterest', 'social'),
+  ('tiktok.com', 'TikTok', 'social'),
+  ('snapchat.com', 'Snapchat', 'social'),
+  ('news.ycombinator.com', 'Hacker News', 'social'),
+  ('bsky.app', 'Bluesky', 'social'),
+  ('mastodon.social', 'Mastodon', 'social'),
+  ('discord.com', 'Discord', 'social'),
+  ('t.me', 'Telegram', 'social'),
+  ('web.telegram.org', 'Telegram', 'social'),
+  ('web.whatsapp.com', 'WhatsApp', 'social'),
+  ('mail.google.com', 'Gmail', 'email'),
+  ('outlook.live.com', 'Outlook', 'email'),
+  ('outlook.office.com', 'Outlook', 'email'),
+  ('outlook.office365.com', 'Outlook', 'email'),
+  ('mail.yahoo.com', 'Yahoo Mail', 'email'),
+  ('mail.proton.me', 'Proton Mail', 'email'),
+  ('mail.zoho.com', 'Zoho Mail', 'email'),
+  ('googleadservices.com', 'Google Ads', 'paid'),
+  ('googlesyndication.com', 'Google Ads', 'paid'),
+  ('doubleclick.net', 'Google Ads', 'paid'),
+  ('bat.bing.com', 'Microsoft Ads', 'paid');
+ALTER TABLE clicks ADD COLUMN referrer_source TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN referrer_channel TEXT NOT NULL DEFAULT '';
+-- Stored clicks are classified by the reclassify-referrers command, with the same rules as new ones
+-- +goose down
+DELETE FROM click_daily_rollups WHERE dimension IN ('channel', 'source');
+DELETE FROM click_hourly_rollups WHERE dimension IN ('channel', 'source');
+ALTER TABLE clicks DROP COLUMN referrer_channel;
+ALTER TABLE clicks DROP COLUMN referrer_source;
+DROP TABLE referrer_sources;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE alert_rules(
+    id UUID PRIMARY KEY UNIQUE NOT NULL,
+    user_id UUID NOT NULL,
+    short_link_id UUID NOT NULL,
+    type TEXT NOT NULL,
+    threshold BIGINT NOT NULL DEFAULT 0,
+    window_minutes INT NOT NULL DEFAULT 0,
+    channel TEXT NOT NULL,
+    is_muted BOOLEAN NOT NULL DEFAULT FALSE,
+    checked_until TIMESTAMP NOT NULL,
+    last_triggered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (short_link_id) REFERENCES short_links(id) ON DELETE CASCADE
+);
+CREATE INDEX alert_rules_user_id_idx ON alert_rules(user_id);
+CREATE INDEX alert_rules_short_link_id_idx ON alert_rules(short_link_id);
+-- +goose down
+DROP TABLE alert_rules;
\ No newline at end of file
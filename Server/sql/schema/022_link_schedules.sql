@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE link_schedules(
+    short_link_id UUID PRIMARY KEY UNIQUE NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    activate_at TIMESTAMP,
+    deactivate_at TIMESTAMP,
+    windows JSONB NOT NULL DEFAULT '[]',
+    fallback_url TEXT NOT NULL DEFAULT '',
+    expiry_notified BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    FOREIGN KEY (short_link_id) REFERENCES short_links(id) ON DELETE CASCADE
+);
+CREATE INDEX link_schedules_deactivate_at_idx ON link_schedules(deactivate_at) WHERE NOT expiry_notified;
+-- +goose down
+DROP TABLE link_schedules;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE fallback_pages(
+    user_id UUID NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('disabled', 'expired', 'blocked')),
+    action TEXT NOT NULL CHECK (action IN ('redirect', 'template', 'status')),
+    url TEXT NOT NULL DEFAULT '',
+    template TEXT NOT NULL DEFAULT '',
+    status_code INT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (user_id, kind),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose down
+DROP TABLE fallback_pages;
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE short_links ADD COLUMN health_action TEXT NOT NULL DEFAULT 'none' CHECK (health_action IN ('none', 'disable', 'fallback'));
+ALTER TABLE short_links ADD COLUMN health_fallback_url TEXT NOT NULL DEFAULT '';
+CREATE TABLE link_health(
+    short_link_id UUID PRIMARY KEY NOT NULL,
+    status TEXT NOT NULL DEFAULT 'unknown',
+    status_code INT,
+    latency_ms INT NOT NULL DEFAULT 0,
+    final_url TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    tls_error TEXT NOT NULL DEFAULT '',
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    checked_at TIMESTAMP,
+    last_healthy_at TIMESTAMP,
+    next_check_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (short_link_id) REFERENCES short_links(id) ON DELETE CASCADE
+);
+CREATE INDEX link_health_next_check_at_idx ON link_health(next_check_at);
+-- +goose down
+DROP TABLE link_health;
+ALTER TABLE short_links DROP COLUMN health_fallback_url;
+ALTER TABLE short_links DROP COLUMN health_action;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE short_links ADD COLUMN quarantined_at TIMESTAMP;
+ALTER TABLE short_links ADD COLUMN quarantine_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE short_links ADD COLUMN quarantined_by TEXT NOT NULL DEFAULT '';
+-- +goose down
+ALTER TABLE short_links DROP COLUMN quarantined_by;
+ALTER TABLE short_links DROP COLUMN quarantine_reason;
+ALTER TABLE short_links DROP COLUMN quarantined_at;
\ No newline at end of file
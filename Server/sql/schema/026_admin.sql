@@ -0,0 +1,45 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'));
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN suspension_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE short_links ADD COLUMN blocked_at TIMESTAMP;
+ALTER TABLE short_links ADD COLUMN blocked_reason TEXT NOT NULL DEFAULT '';
+CREATE TABLE abuse_reports(
+    id UUID PRIMARY KEY UNIQUE NOT NULL,
+    short_link_id UUID,
+    slug TEXT NOT NULL,
+    category TEXT NOT NULL CHECK (category IN ('phishing', 'malware', 'spam', 'illegal', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    reporter_email TEXT NOT NULL DEFAULT '',
+    reporter_ip_hash TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    resolution_note TEXT NOT NULL DEFAULT '',
+    resolved_by UUID,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (short_link_id) REFERENCES short_links(id) ON DELETE SET NULL
+);
+CREATE INDEX abuse_reports_status_created_at_idx ON abuse_reports(status, created_at);
+CREATE INDEX abuse_reports_reporter_ip_hash_idx ON abuse_reports(reporter_ip_hash, created_at);
+CREATE TABLE audit_log(
+    id UUID PRIMARY KEY UNIQUE NOT NULL,
+    actor_id UUID,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    before JSONB NOT NULL DEFAULT '{}',
+    after JSONB NOT NULL DEFAULT '{}',
+    ip_address TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX audit_log_created_at_idx ON audit_log(created_at);
+CREATE INDEX audit_log_actor_id_idx ON audit_log(actor_id, created_at);
+-- +goose down
+DROP TABLE audit_log;
+DROP TABLE abuse_reports;
+ALTER TABLE short_links DROP COLUMN blocked_reason;
+ALTER TABLE short_links DROP COLUMN blocked_at;
+ALTER TABLE users DROP COLUMN suspension_reason;
+ALTER TABLE users DROP COLUMN suspended_at;
+ALTER TABLE users DROP COLUMN role;
\ No newline at end of file
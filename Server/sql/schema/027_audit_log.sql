@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE audit_log ADD COLUMN account_id UUID;
+UPDATE audit_log SET account_id = target_id::uuid WHERE target_type = 'user';
+UPDATE audit_log SET account_id = short_links.user_id
+FROM short_links
+WHERE audit_log.target_type = 'link' AND short_links.id::text = audit_log.target_id;
+CREATE INDEX audit_log_account_id_idx ON audit_log(account_id, created_at);
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+-- +goose down
+DROP TRIGGER audit_log_no_truncate ON audit_log;
+DROP TRIGGER audit_log_no_update ON audit_log;
+DROP FUNCTION audit_log_append_only();
+DROP INDEX audit_log_account_id_idx;
+ALTER TABLE audit_log DROP COLUMN account_id;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE short_links ADD COLUMN deleted_at TIMESTAMP;
+CREATE INDEX short_links_deleted_at_idx ON short_links(deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose down
+DROP INDEX short_links_deleted_at_idx;
+ALTER TABLE short_links DROP COLUMN deleted_at;
\ No newline at end of file
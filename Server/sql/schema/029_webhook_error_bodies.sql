@@ -0,0 +1,7 @@
+-- +goose Up
+-- Response bodies could come from an internal service, so only the status is kept
+UPDATE webhook_deliveries
+SET last_error = regexp_replace(last_error, '^(endpoint returned [0-9]+):.*$', '\1')
+WHERE last_error LIKE 'endpoint returned %:%';
+-- +goose down
+SELECT 1;
@@ -0,0 +1,54 @@
+-- +goose Up
+-- Snapshot fields that describe the account's own content rather than what was done
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_personal_fields() RETURNS text[] AS $$
+    SELECT ARRAY['name', 'email', 'slug', 'original_url', 'title', 'utm_source', 'utm_medium', 'utm_campaign',
+        'utm_term', 'utm_content', 'extra_params'];
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+-- Append-only, except that scrub_audit_log_account may empty columns holding personal data
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_setting('audit_log.scrubbing', true) = 'on'
+        AND NEW.id = OLD.id AND NEW.action = OLD.action AND NEW.target_type = OLD.target_type
+        AND NEW.target_id = OLD.target_id AND NEW.created_at = OLD.created_at
+        AND (NEW.actor_id IS NOT DISTINCT FROM OLD.actor_id OR NEW.actor_id IS NULL)
+        AND (NEW.account_id IS NOT DISTINCT FROM OLD.account_id OR NEW.account_id IS NULL)
+        AND (NEW.ip_address = OLD.ip_address OR NEW.ip_address = '')
+        AND (NEW.user_agent = OLD.user_agent OR NEW.user_agent = '')
+        AND (NEW.before = OLD.before OR NEW.before = OLD.before - audit_log_personal_fields())
+        AND (NEW.after = OLD.after OR NEW.after = OLD.after - audit_log_personal_fields()) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+-- Erases a purged account's addresses, content and references; the entries themselves stay
+-- +goose StatementBegin
+CREATE FUNCTION scrub_audit_log_account(account UUID) RETURNS void AS $$
+BEGIN
+    PERFORM set_config('audit_log.scrubbing', 'on', true);
+    UPDATE audit_log
+    SET actor_id = CASE WHEN actor_id = account THEN NULL ELSE actor_id END,
+        account_id = CASE WHEN account_id = account THEN NULL ELSE account_id END,
+        ip_address = CASE WHEN actor_id = account THEN '' ELSE ip_address END,
+        user_agent = CASE WHEN actor_id = account THEN '' ELSE user_agent END,
+        before = CASE WHEN account_id = account THEN before - audit_log_personal_fields() ELSE before END,
+        after = CASE WHEN account_id = account THEN after - audit_log_personal_fields() ELSE after END
+    WHERE actor_id = account OR account_id = account;
+    PERFORM set_config('audit_log.scrubbing', 'off', true);
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+-- +goose StatementEnd
+-- +goose down
+DROP FUNCTION scrub_audit_log_account(UUID);
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+DROP FUNCTION audit_log_personal_fields();
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const oidcStateExpiry = 10 * time.Minute
+
+var (
+	errUnverifiedProviderEmail = errors.New("identity provider did not return a verified email")
+	errUnverifiedLocalAccount  = errors.New("an account with this email exists but its email is not verified; sign in with your password and verify it first")
+)
+
+func (cfg *apiCfg) oidcProviderFromParam(c *gin.Context) (*oidcProvider, bool) {
+	provider, ok := cfg.oidcProviders[strings.ToLower(c.Param("provider"))]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown identity provider"})
+	}
+	return provider, ok
+}
+
+func (cfg *apiCfg) listOIDCProviders(c *gin.Context) {
+	names := []string{}
+	for name := range cfg.oidcProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c.JSON(http.StatusOK, gin.H{"data": names})
+}
+
+func (cfg *apiCfg) startOIDCLogin(c *gin.Context) {
+	provider, ok := cfg.oidcProviderFromParam(c)
+	if !ok {
+		return
+	}
+	state, err := generateSecureToken()
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	nonce, err := generateSecureToken()
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	codeVerifier, err := generateSecureToken()
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	authURL, err := provider.authorizationURL(c, state, nonce, codeVerifier)
+	if err != nil {
+		c.AbortWithError(http.StatusBadGateway, err)
+		return
+	}
+	if err := cfg.db.DeleteExpiredOidcStates(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	err = cfg.db.CreateOidcState(c, database.CreateOidcStateParams{
+		StateHash:    hashToken(state),
+		Provider:     provider.name,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(oidcStateExpiry),
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, OIDCStartRes{AuthorizationURL: authURL})
+}
+
+// The queries signing in through a provider needs; *database.Queries implements it
+type oidcUserStore interface {
+	RetrieveUserIdentity(ctx context.Context, arg database.RetrieveUserIdentityParams) (database.UserIdentity, error)
+	RetrieveUserById(ctx context.Context, id uuid.UUID) (database.User, error)
+	RetrieveUserByEmail(ctx context.Context, email string) (database.User, error)
+	CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error)
+	VerifyUserEmail(ctx context.Context, id uuid.UUID) error
+	CreateUserIdentity(ctx context.Context, arg database.CreateUserIdentityParams) error
+}
+
+// Finds or provisions the account for a provider identity; unverified local emails are never linked
+func userForOIDCIdentity(ctx context.Context, store oidcUserStore, provider string, claims *oidcClaims) (database.User, error) {
+	identity, err := store.RetrieveUserIdentity(ctx, database.RetrieveUserIdentityParams{
+		Provider: provider,
+		Subject:  claims.Subject,
+	})
+	if err == nil {
+		return store.RetrieveUserById(ctx, identity.UserID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, err
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return database.User{}, errUnverifiedProviderEmail
+	}
+	user, err := store.RetrieveUserByEmail(ctx, claims.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		user, err = provisionOIDCUser(ctx, store, claims)
+	} else if err == nil && !user.EmailVerified {
+		return database.User{}, errUnverifiedLocalAccount
+	}
+	if err != nil {
+		return database.User{}, err
+	}
+	err = store.CreateUserIdentity(ctx, database.CreateUserIdentityParams{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+	return user, nil
+}
+
+func provisionOIDCUser(ctx context.Context, store oidcUserStore, claims *oidcClaims) (database.User, error) {
+	// SSO accounts get a random password nobody knows; a reset mail can set a real one later
+	randomPassword, err := generateSecureToken()
+	if err != nil {
+		return database.User{}, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return database.User{}, err
+	}
+	name := claims.Name
+	if name == "" {
+		name = strings.Split(claims.Email, "@")[0]
+	}
+	user, err := store.CreateUser(ctx, database.CreateUserParams{
+		Name:     name,
+		Email:    claims.Email,
+		Password: string(hashedPassword),
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+	if err := store.VerifyUserEmail(ctx, user.ID); err != nil {
+		return database.User{}, err
+	}
+	user.EmailVerified = true
+	return user, nil
+}
+
+func (cfg *apiCfg) finishOIDCLogin(c *gin.Context) {
+	provider, ok := cfg.oidcProviderFromParam(c)
+	if !ok {
+		return
+	}
+	var data OIDCCallbackReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	state, err := cfg.db.ConsumeOidcState(c, database.ConsumeOidcStateParams{
+		StateHash: hashToken(data.State),
+		Provider:  provider.name,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired login state"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	rawIDToken, err := provider.exchangeCode(c, data.Code, state.CodeVerifier)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to exchange authorization code"})
+		return
+	}
+	claims, err := provider.verifyIDToken(c, rawIDToken, state.Nonce)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid ID token"})
+		return
+	}
+	user, err := userForOIDCIdentity(c, cfg.db, provider.name, claims)
+	if err != nil {
+		if errors.Is(err, errUnverifiedProviderEmail) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Identity provider did not return a verified email"})
+			return
+		}
+		if errors.Is(err, errUnverifiedLocalAccount) {
+			c.JSON(http.StatusConflict, gin.H{"error": errUnverifiedLocalAccount.Error()})
+			return
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			if pqErr.Code == "23505" { // Unique violation
+				c.JSON(http.StatusConflict, gin.H{"error": "Identity is already linked"})
+				return
+			}
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if user.MfaEnabled {
+		challengeToken, err := createMfaChallengeToken(user.ID, cfg.jwtSecret)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.JSON(http.StatusOK, MfaChallengeRes{Status: "mfa_required", ChallengeToken: challengeToken})
+		return
+	}
+	cfg.recordLoginAttempt(c, user.ID, user.Email, true)
+	session, err := cfg.startSession(c, user)
+	if errors.Is(err, errAccountSuspended) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// A local OpenID Connect provider handing out the ID token registered for each code
+type mockOIDCServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	tokens map[string]oidcClaims
+}
+
+func newMockOIDCServer(t *testing.T) *mockOIDCServer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockOIDCServer{key: key, tokens: map[string]oidcClaims{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                m.URL,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JWKSURI:               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]oidcJWK{"keys": {{
+			Kid: "test",
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		claims, ok := m.tokens[r.PostFormValue("code")]
+		m.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(oidcTokenRes{Error: "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(oidcTokenRes{IDToken: signed})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mockOIDCServer) provider() *oidcProvider {
+	return &oidcProvider{
+		name:     "mock",
+		issuer:   m.URL,
+		clientID: "client",
+		client:   m.Client(),
+	}
+}
+
+// Registers an authorization code for a user of the provider
+func (m *mockOIDCServer) authorize(code, subject, email string, emailVerified bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[code] = oidcClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.URL,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{"client"},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Nonce:         "nonce",
+		Email:         email,
+		EmailVerified: emailVerified,
+		Name:          "Mock User",
+	}
+}
+
+// Runs the code exchange and ID token checks the callback does
+func (m *mockOIDCServer) signIn(t *testing.T, code string) *oidcClaims {
+	t.Helper()
+	p := m.provider()
+	raw, err := p.exchangeCode(context.Background(), code, "verifier")
+	if err != nil {
+		t.Fatalf("exchangeCode: %v", err)
+	}
+	claims, err := p.verifyIDToken(context.Background(), raw, "nonce")
+	if err != nil {
+		t.Fatalf("verifyIDToken: %v", err)
+	}
+	return claims
+}
+
+type fakeOIDCStore struct {
+	users      map[uuid.UUID]database.User
+	identities []database.CreateUserIdentityParams
+}
+
+func newFakeOIDCStore(users ...database.User) *fakeOIDCStore {
+	s := &fakeOIDCStore{users: map[uuid.UUID]database.User{}}
+	for _, user := range users {
+		s.users[user.ID] = user
+	}
+	return s
+}
+
+func (s *fakeOIDCStore) RetrieveUserIdentity(ctx context.Context, arg database.RetrieveUserIdentityParams) (database.UserIdentity, error) {
+	for _, identity := range s.identities {
+		if identity.Provider == arg.Provider && identity.Subject == arg.Subject {
+			return database.UserIdentity{UserID: identity.UserID, Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email}, nil
+		}
+	}
+	return database.UserIdentity{}, sql.ErrNoRows
+}
+
+func (s *fakeOIDCStore) RetrieveUserById(ctx context.Context, id uuid.UUID) (database.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (s *fakeOIDCStore) RetrieveUserByEmail(ctx context.Context, email string) (database.User, error) {
+	for _, user := range s.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *fakeOIDCStore) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	user := database.User{ID: uuid.New(), Name: arg.Name, Email: arg.Email, Password: arg.Password}
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *fakeOIDCStore) VerifyUserEmail(ctx context.Context, id uuid.UUID) error {
+	user := s.users[id]
+	user.EmailVerified = true
+	s.users[id] = user
+	return nil
+}
+
+func (s *fakeOIDCStore) CreateUserIdentity(ctx context.Context, arg database.CreateUserIdentityParams) error {
+	s.identities = append(s.identities, arg)
+	return nil
+}
+
+func TestOIDCSignInProvisionsNewUser(t *testing.T) {
+	server := newMockOIDCServer(t)
+	server.authorize("code", "sub-1", "new@example.com", true)
+	store := newFakeOIDCStore()
+
+	user, err := userForOIDCIdentity(context.Background(), store, "mock", server.signIn(t, "code"))
+	if err != nil {
+		t.Fatalf("userForOIDCIdentity: %v", err)
+	}
+	if user.Email != "new@example.com" || !user.EmailVerified {
+		t.Fatalf("provisioned user = %+v, want verified new@example.com", user)
+	}
+	if len(store.identities) != 1 || store.identities[0].UserID != user.ID {
+		t.Fatalf("identities = %+v, want one linked to the new user", store.identities)
+	}
+
+	// Signing in again finds the account through the identity
+	again, err := userForOIDCIdentity(context.Background(), store, "mock", server.signIn(t, "code"))
+	if err != nil {
+		t.Fatalf("second sign-in: %v", err)
+	}
+	if again.ID != user.ID || len(store.users) != 1 {
+		t.Fatalf("second sign-in returned %v with %d users, want the same single user", again.ID, len(store.users))
+	}
+}
+
+func TestOIDCSignInLinksVerifiedAccount(t *testing.T) {
+	server := newMockOIDCServer(t)
+	server.authorize("code", "sub-1", "owner@example.com", true)
+	existing := database.User{ID: uuid.New(), Email: "owner@example.com", Password: "hash", EmailVerified: true}
+	store := newFakeOIDCStore(existing)
+
+	user, err := userForOIDCIdentity(context.Background(), store, "mock", server.signIn(t, "code"))
+	if err != nil {
+		t.Fatalf("userForOIDCIdentity: %v", err)
+	}
+	if user.ID != existing.ID {
+		t.Fatalf("signed in as %v, want existing account %v", user.ID, existing.ID)
+	}
+	if len(store.identities) != 1 || store.identities[0].UserID != existing.ID {
+		t.Fatalf("identities = %+v, want one linked to the existing account", store.identities)
+	}
+}
+
+func TestOIDCSignInRefusesUnverifiedAccount(t *testing.T) {
+	server := newMockOIDCServer(t)
+	server.authorize("code", "sub-1", "victim@example.com", true)
+	// Registered with a password by someone who never proved they own the address
+	squatter := database.User{ID: uuid.New(), Email: "victim@example.com", Password: "hash"}
+	store := newFakeOIDCStore(squatter)
+
+	_, err := userForOIDCIdentity(context.Background(), store, "mock", server.signIn(t, "code"))
+	if !errors.Is(err, errUnverifiedLocalAccount) {
+		t.Fatalf("err = %v, want errUnverifiedLocalAccount", err)
+	}
+	if len(store.identities) != 0 {
+		t.Fatalf("identities = %+v, want none", store.identities)
+	}
+}
+
+func TestOIDCSignInRequiresVerifiedProviderEmail(t *testing.T) {
+	server := newMockOIDCServer(t)
+	server.authorize("code", "sub-1", "owner@example.com", false)
+	existing := database.User{ID: uuid.New(), Email: "owner@example.com", EmailVerified: true}
+	store := newFakeOIDCStore(existing)
+
+	_, err := userForOIDCIdentity(context.Background(), store, "mock", server.signIn(t, "code"))
+	if !errors.Is(err, errUnverifiedProviderEmail) {
+		t.Fatalf("err = %v, want errUnverifiedProviderEmail", err)
+	}
+	if len(store.identities) != 0 {
+		t.Fatalf("identities = %+v, want none", store.identities)
+	}
+}
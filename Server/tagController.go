@@ -0,0 +1,273 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const maxBulkLinks = 1000
+
+func tagIDFromParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func tagListRes(tags []database.ListTagsByUserIdRow) []TagRes {
+	data := []TagRes{}
+	for _, tag := range tags {
+		data = append(data, TagRes{
+			ID:        tag.ID,
+			Name:      tag.Name,
+			Color:     tag.Color,
+			LinkCount: tag.LinkCount,
+			CreatedAt: tag.CreatedAt.String(),
+		})
+	}
+	return data
+}
+
+func (cfg *apiCfg) GetTags(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	tags, err := cfg.db.ListTagsByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tagListRes(tags)})
+}
+
+func (cfg *apiCfg) CreateTag(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data TagReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	name := strings.TrimSpace(data.Name)
+	if err := validateTag(name, data.Color); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tag, err := cfg.db.CreateTag(c, database.CreateTagParams{
+		UserID: user.ID,
+		Name:   name,
+		Color:  data.Color,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			if pqErr.Code == "23505" { // Unique violation
+				c.JSON(http.StatusConflict, gin.H{"error": "Tag already exists"})
+				return
+			}
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, TagRes{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		Color:     tag.Color,
+		CreatedAt: tag.CreatedAt.String(),
+	})
+}
+
+func (cfg *apiCfg) UpdateTag(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	id, ok := tagIDFromParam(c)
+	if !ok {
+		return
+	}
+	var data TagReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	name := strings.TrimSpace(data.Name)
+	if err := validateTag(name, data.Color); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	affected, err := cfg.db.UpdateTag(c, database.UpdateTagParams{
+		UserID: user.ID,
+		ID:     id,
+		Name:   name,
+		Color:  data.Color,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			if pqErr.Code == "23505" { // Unique violation
+				c.JSON(http.StatusConflict, gin.H{"error": "Tag already exists"})
+				return
+			}
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) DeleteTag(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	id, ok := tagIDFromParam(c)
+	if !ok {
+		return
+	}
+	affected, err := cfg.db.DeleteTagByUserIdANDId(c, database.DeleteTagByUserIdANDIdParams{
+		UserID: user.ID,
+		ID:     id,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) GetTagAnalytics(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	id, ok := tagIDFromParam(c)
+	if !ok {
+		return
+	}
+	tag, err := cfg.db.RetrieveTagByUserIdANDId(c, database.RetrieveTagByUserIdANDIdParams{
+		UserID: user.ID,
+		ID:     id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	rolledUpTo, err := cfg.clickRollupWatermark(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	rollups, err := cfg.db.DailyRollupsByTag(c, database.DailyRollupsByTagParams{
+		TagID:  tag.ID,
+		Bucket: rolledUpTo,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	rows, err := cfg.db.AnalyticsRetrievalByTag(c, database.AnalyticsRetrievalByTagParams{
+		TagID:     tag.ID,
+		CreatedAt: rolledUpTo,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	analyticsData := make([]database.AnalyticsRetrievalRow, 0, len(rows))
+	for _, row := range rows {
+		analyticsData = append(analyticsData, database.AnalyticsRetrievalRow(row))
+	}
+	data := newAnalytics()
+	addRollupData(&data, rollups)
+	sortAnalyticsData(&data, analyticsData)
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// Adds and removes tags on a batch of links in one request
+func (cfg *apiCfg) UpdateLinkTags(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data LinkTagsReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	if len(data.Slugs) == 0 || len(data.Slugs) > maxBulkLinks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slugs must contain between 1 and 1000 links"})
+		return
+	}
+	if len(data.Add) == 0 && len(data.Remove) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to add or remove"})
+		return
+	}
+	ids := map[uuid.UUID]bool{}
+	for _, id := range append(append([]uuid.UUID{}, data.Add...), data.Remove...) {
+		ids[id] = true
+	}
+	unique := make([]uuid.UUID, 0, len(ids))
+	for id := range ids {
+		unique = append(unique, id)
+	}
+	owned, err := cfg.db.CountTagsByUserIdANDIds(c, database.CountTagsByUserIdANDIdsParams{
+		UserID: user.ID,
+		Ids:    unique,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if owned != int64(len(unique)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return
+	}
+	var changed []uuid.UUID
+	if len(data.Add) > 0 {
+		added, err := cfg.db.AddTagsToShortLinks(c, database.AddTagsToShortLinksParams{
+			UserID: user.ID,
+			Slugs:  data.Slugs,
+			TagIds: data.Add,
+		})
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		changed = append(changed, added...)
+	}
+	if len(data.Remove) > 0 {
+		removed, err := cfg.db.RemoveTagsFromShortLinks(c, database.RemoveTagsFromShortLinksParams{
+			UserID: user.ID,
+			Slugs:  data.Slugs,
+			TagIds: data.Remove,
+		})
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		changed = append(changed, removed...)
+	}
+	updated := int64(len(changed))
+	emitted := map[uuid.UUID]bool{}
+	for _, id := range changed {
+		if emitted[id] {
+			continue
+		}
+		emitted[id] = true
+		link, err := cfg.db.RetrieveShortLinkById(c, id)
+		if err != nil {
+			log.Printf("Failed to load updated link: %v", err)
+			continue
+		}
+		cfg.emitLinkEvent(c, webhookLinkUpdated, link)
+	}
+	c.JSON(http.StatusOK, BulkUpdateRes{Success: true, Updated: updated})
+}
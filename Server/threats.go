@@ -0,0 +1,408 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	pathpkg "path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// Threat types as Safe Browsing names them
+	threatMalware           = "MALWARE"
+	threatSocialEngineering = "SOCIAL_ENGINEERING"
+	threatUnwantedSoftware  = "UNWANTED_SOFTWARE"
+	threatUnspecified       = "THREAT_TYPE_UNSPECIFIED"
+
+	quarantinedByScanner = "scanner"
+	quarantinedByAdmin   = "admin"
+
+	threatReloadInterval = time.Minute
+	threatScanInterval   = time.Hour
+	threatScanBatchSize  = 500
+	// Safe Browsing looks at the exact host and at most four of its parents
+	maxThreatHostSuffixes = 5
+	maxThreatPathPrefixes = 4
+)
+
+var threatLabels = map[string]string{
+	threatMalware:           "malware",
+	threatSocialEngineering: "phishing",
+	threatUnwantedSoftware:  "unwanted software",
+	threatUnspecified:       "a malicious site",
+}
+
+type ThreatMatch struct {
+	ThreatType string
+	// The list entry that matched: a domain, or the URL expression whose hash is listed
+	Pattern string
+}
+
+// A nil match means the URL is not listed
+type ThreatChecker interface {
+	Check(ctx context.Context, rawURL string) (*ThreatMatch, error)
+}
+
+// Local domain and URL-hash lists in the spirit of Safe Browsing, reloaded on change
+type LocalThreatList struct {
+	domainsFile string
+	hashesFile  string
+
+	mu      sync.RWMutex
+	domains map[string]string
+	hashes  map[string]string
+	modTime map[string]time.Time
+}
+
+// SIGHUP forces a full reload, for files replaced within the same second
+func reloadOnHangup(list *LocalThreatList) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		if err := list.Reload(true); err != nil {
+			log.Printf("Failed to reload threat lists: %v", err)
+		}
+	}
+}
+
+func newThreatListFromEnv() *LocalThreatList {
+	return &LocalThreatList{
+		domainsFile: os.Getenv("THREAT_DOMAINS_FILE"),
+		hashesFile:  os.Getenv("THREAT_URL_HASHES_FILE"),
+		domains:     map[string]string{},
+		hashes:      map[string]string{},
+		modTime:     map[string]time.Time{},
+	}
+}
+
+// A list that fails to load keeps its previous entries
+func (l *LocalThreatList) Reload(force bool) error {
+	var errs []error
+	for _, file := range []string{l.domainsFile, l.hashesFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		l.mu.RLock()
+		unchanged := l.modTime[file].Equal(info.ModTime())
+		l.mu.RUnlock()
+		if unchanged && !force {
+			continue
+		}
+		entries, err := readThreatList(file, file == l.hashesFile)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		l.mu.Lock()
+		if file == l.hashesFile {
+			l.hashes = entries
+		} else {
+			l.domains = entries
+		}
+		l.modTime[file] = info.ModTime()
+		l.mu.Unlock()
+		log.Printf("Loaded %d threat list entries from %s", len(entries), file)
+	}
+	return errors.Join(errs...)
+}
+
+func readThreatList(file string, hashes bool) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		entry := strings.ToLower(fields[0])
+		threatType := threatUnspecified
+		if len(fields) > 1 {
+			threatType = strings.ToUpper(fields[1])
+			if _, ok := threatLabels[threatType]; !ok {
+				return nil, fmt.Errorf("%s:%d: unknown threat type %q", file, line, fields[1])
+			}
+		}
+		if hashes {
+			if decoded, err := hex.DecodeString(entry); err != nil || len(decoded) != sha256.Size {
+				return nil, fmt.Errorf("%s:%d: %q is not a hex SHA-256 hash", file, line, fields[0])
+			}
+		} else {
+			entry = strings.Trim(entry, ".")
+		}
+		entries[entry] = threatType
+	}
+	return entries, scanner.Err()
+}
+
+func (l *LocalThreatList) Check(ctx context.Context, rawURL string) (*ThreatMatch, error) {
+	host, expressions, err := threatExpressions(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for domain := host; domain != ""; {
+		if threatType, ok := l.domains[domain]; ok {
+			return &ThreatMatch{ThreatType: threatType, Pattern: domain}, nil
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	for _, expression := range expressions {
+		sum := sha256.Sum256([]byte(expression))
+		if threatType, ok := l.hashes[hex.EncodeToString(sum[:])]; ok {
+			return &ThreatMatch{ThreatType: threatType, Pattern: expression}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Escapes control characters, space, non-ASCII bytes, "#" and "%" like Safe Browsing
+func escapeThreatPart(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || c == '#' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// Unescapes until nothing changes, so "%2541" and "%41" end up the same
+func unescapeRepeatedly(s string) string {
+	for i := 0; i < 10; i++ {
+		unescaped, err := url.PathUnescape(s)
+		if err != nil || unescaped == s {
+			return s
+		}
+		s = unescaped
+	}
+	return s
+}
+
+// Safe Browsing host/path lookup expressions for the canonical URL
+func threatExpressions(rawURL string) (string, []string, error) {
+	rawURL = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(rawURL))
+	rawURL, _, _ = strings.Cut(rawURL, "#")
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil, err
+	}
+	host := strings.ToLower(unescapeRepeatedly(u.Hostname()))
+	host = strings.Trim(host, ".")
+	for strings.Contains(host, "..") {
+		host = strings.ReplaceAll(host, "..", ".")
+	}
+	if host == "" {
+		return "", nil, errors.New("URL has no host")
+	}
+	path := unescapeRepeatedly(u.EscapedPath())
+	clean := pathpkg.Clean("/" + path)
+	if strings.HasSuffix(path, "/") && clean != "/" {
+		clean += "/"
+	}
+	path = escapeThreatPart(clean)
+	query := ""
+	if u.RawQuery != "" {
+		query = "?" + escapeThreatPart(unescapeRepeatedly(u.RawQuery))
+	}
+
+	hosts := []string{host}
+	if net.ParseIP(host) == nil {
+		labels := strings.Split(host, ".")
+		if len(labels) > maxThreatHostSuffixes {
+			labels = labels[len(labels)-maxThreatHostSuffixes:]
+		}
+		for i := 0; i < len(labels)-1; i++ {
+			if suffix := strings.Join(labels[i:], "."); suffix != host {
+				hosts = append(hosts, suffix)
+			}
+		}
+	}
+	paths := []string{}
+	if query != "" {
+		paths = append(paths, path+query)
+	}
+	paths = append(paths, path)
+	dirs := strings.Split(strings.Trim(path, "/"), "/")
+	if !strings.HasSuffix(path, "/") {
+		dirs = dirs[:len(dirs)-1]
+	}
+	prefix := "/"
+	for i := 0; i < maxThreatPathPrefixes; i++ {
+		if prefix != path {
+			paths = append(paths, prefix)
+		}
+		if i >= len(dirs) || dirs[i] == "" {
+			break
+		}
+		prefix += dirs[i] + "/"
+	}
+	var expressions []string
+	for _, h := range hosts {
+		for _, p := range paths {
+			expressions = append(expressions, h+p)
+		}
+	}
+	return host, expressions, nil
+}
+
+func threatReason(match *ThreatMatch) string {
+	return "Destination flagged as " + threatLabels[match.ThreatType]
+}
+
+// Checks the destination and every fallback URL the link can send visitors to
+func (cfg *apiCfg) checkLinkDestinations(ctx context.Context, link database.ShortLink) (*ThreatMatch, string, error) {
+	destination, err := buildDestinationURL(link, "")
+	if err != nil {
+		destination = link.OriginalUrl
+	}
+	match, err := cfg.threats.Check(ctx, destination)
+	if err != nil {
+		return nil, "", err
+	}
+	if match != nil {
+		return match, threatReason(match), nil
+	}
+	fallbacks, err := cfg.db.ListShortLinkFallbackUrls(ctx, link.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, fallback := range fallbacks {
+		match, err := cfg.threats.Check(ctx, fallback)
+		if err != nil {
+			return nil, "", err
+		}
+		if match != nil {
+			return match, "Fallback URL flagged as " + threatLabels[match.ThreatType], nil
+		}
+	}
+	return nil, "", nil
+}
+
+// Quarantines or releases the link; only the scanner's own quarantines are lifted
+func (cfg *apiCfg) screenLink(ctx context.Context, link database.ShortLink) (database.ShortLink, error) {
+	match, reason, err := cfg.checkLinkDestinations(ctx, link)
+	if err != nil {
+		return link, err
+	}
+	switch {
+	case match != nil && !link.QuarantinedAt.Valid:
+		quarantined, err := cfg.db.QuarantineShortLink(ctx, database.QuarantineShortLinkParams{
+			ID:               link.ID,
+			QuarantineReason: reason,
+			QuarantinedBy:    quarantinedByScanner,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return link, nil
+		}
+		if err != nil {
+			return link, err
+		}
+		log.Printf("Quarantined /%s: %s matched %s", link.Slug, match.ThreatType, match.Pattern)
+		cfg.emitLinkEvent(ctx, webhookLinkQuarantined, quarantined)
+		cfg.notifyQuarantine(ctx, quarantined)
+		return quarantined, nil
+	case match == nil && link.QuarantinedAt.Valid && link.QuarantinedBy == quarantinedByScanner:
+		released, err := cfg.db.ReleaseShortLinkQuarantine(ctx, database.ReleaseShortLinkQuarantineParams{
+			ID:            link.ID,
+			QuarantinedBy: quarantinedByScanner,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return link, nil
+		}
+		if err != nil {
+			return link, err
+		}
+		cfg.emitLinkEvent(ctx, webhookLinkUpdated, released)
+		return released, nil
+	}
+	return link, nil
+}
+
+// A failing checker lets the link through until the next re-scan
+func (cfg *apiCfg) screenLinkOnSave(ctx context.Context, link database.ShortLink) database.ShortLink {
+	screened, err := cfg.screenLink(ctx, link)
+	if err != nil {
+		log.Printf("Failed to scan link destination: %v", err)
+	}
+	return screened
+}
+
+func (cfg *apiCfg) notifyQuarantine(ctx context.Context, link database.ShortLink) {
+	cfg.notifyModeration(ctx, link, "Link /"+link.Slug+" quarantined",
+		"has been quarantined: "+strings.ToLower(link.QuarantineReason)+". Visitors now see a warning page instead of being redirected.")
+}
+
+// Catches newly listed destinations and releases delisted ones
+func (cfg *apiCfg) rescanLinks(ctx context.Context) error {
+	afterID := uuid.Nil
+	for {
+		links, err := cfg.db.ListShortLinksForThreatScan(ctx, database.ListShortLinksForThreatScanParams{
+			AfterID:   afterID,
+			BatchSize: threatScanBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if _, err := cfg.screenLink(ctx, link); err != nil {
+				log.Printf("Failed to scan link destination: %v", err)
+			}
+		}
+		if len(links) < threatScanBatchSize {
+			return nil
+		}
+		afterID = links[len(links)-1].ID
+	}
+}
+
+func quarantinePage(link database.ShortLink) database.FallbackPage {
+	return database.FallbackPage{
+		Kind:       fallbackQuarantined,
+		Action:     fallbackActionTemplate,
+		Template:   "interstitial",
+		StatusCode: http.StatusForbidden,
+		Title:      "This link has been blocked",
+		Message:    strings.TrimSuffix(link.QuarantineReason, ".") + ". We stopped redirecting to it to keep you safe.",
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Trashed links keep their slug and clicks this long
+	defaultTrashDays    = 30
+	maxTrashDays        = 3650
+	trashPurgeInterval  = time.Hour
+	trashPurgeBatchSize = 500
+)
+
+func trashDaysFromEnv() int {
+	value := os.Getenv("LINK_TRASH_DAYS")
+	if value == "" {
+		return defaultTrashDays
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 || days > maxTrashDays {
+		log.Fatal("LINK_TRASH_DAYS must be between 1 and 3650")
+	}
+	return days
+}
+
+// Purges expired trash in batches and quarantines the slugs
+func (cfg *apiCfg) purgeTrash(ctx context.Context) error {
+	for {
+		purged, err := cfg.purgeTrashBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if purged < trashPurgeBatchSize {
+			return nil
+		}
+	}
+}
+
+func (cfg *apiCfg) purgeTrashBatch(ctx context.Context) (int, error) {
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	q := cfg.db.WithTx(tx)
+	links, err := q.PurgeTrashedShortLinks(ctx, database.PurgeTrashedShortLinksParams{
+		TrashDays: int32(cfg.trashDays),
+		BatchSize: trashPurgeBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, link := range links {
+		if err := q.QuarantineSlug(ctx, database.QuarantineSlugParams{
+			Slug:           link.Slug,
+			QuarantineDays: slugQuarantineDays,
+		}); err != nil {
+			return 0, err
+		}
+		if err := writeJobAudit(ctx, q, auditEntry{
+			Action:     auditLinkPurge,
+			TargetType: auditTargetLink,
+			TargetID:   link.ID.String(),
+			AccountID:  link.UserID,
+			Before:     linkAuditState(link),
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(links), tx.Commit()
+}
+
+func (cfg *apiCfg) trashedLinkRes(link database.ShortLink) TrashedLinkRes {
+	return TrashedLinkRes{
+		Slug:        link.Slug,
+		OriginalURL: link.OriginalUrl,
+		Title:       link.Title,
+		CreatedAt:   link.CreatedAt,
+		DeletedAt:   link.DeletedAt.Time,
+		PurgeAt:     link.DeletedAt.Time.AddDate(0, 0, cfg.trashDays),
+	}
+}
+
+// Most recently deleted first, with when each is purged
+func (cfg *apiCfg) GetTrash(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	limit, offset, err := offsetPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	links, err := cfg.db.ListTrashedShortLinksByUserId(c, database.ListTrashedShortLinksByUserIdParams{
+		UserID:     user.ID,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data := []TrashedLinkRes{}
+	for _, link := range links {
+		data = append(data, cfg.trashedLinkRes(link))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// Takes the link out of the trash with its settings and clicks as they were
+func (cfg *apiCfg) RestoreLink(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	slug := c.Param("slug")
+	if slug == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	var link database.ShortLink
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		var err error
+		link, err = q.RestoreShortLinkBySlugNUserId(c, database.RestoreShortLinkBySlugNUserIdParams{
+			Slug:   slug,
+			UserID: user.ID,
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		trashed := link
+		trashed.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		return auditEntry{
+			Action:     auditLinkRestore,
+			TargetType: auditTargetLink,
+			TargetID:   link.ID.String(),
+			AccountID:  user.ID,
+			Before:     linkAuditState(trashed),
+			After:      linkAuditState(link),
+		}, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found in trash"})
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	cfg.emitLinkEvent(c, webhookLinkUpdated, link)
+	// The threat lists may have changed while the link was away
+	cfg.screenLinkOnSave(c, link)
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// Its slug stays quarantined so nobody else can take it over right away
+func (cfg *apiCfg) PurgeLink(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	slug := c.Param("slug")
+	if slug == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	err := cfg.auditedTx(c, func(q *database.Queries) (auditEntry, error) {
+		link, err := q.DeleteTrashedShortLinkBySlugNUserId(c, database.DeleteTrashedShortLinkBySlugNUserIdParams{
+			Slug:   slug,
+			UserID: user.ID,
+		})
+		if err != nil {
+			return auditEntry{}, err
+		}
+		if err := q.QuarantineSlug(c, database.QuarantineSlugParams{
+			Slug:           link.Slug,
+			QuarantineDays: slugQuarantineDays,
+		}); err != nil {
+			return auditEntry{}, err
+		}
+		return auditEntry{
+			Action:     auditLinkPurge,
+			TargetType: auditTargetLink,
+			TargetID:   link.ID.String(),
+			AccountID:  user.ID,
+			Before:     linkAuditState(link),
+		}, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found in trash"})
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
@@ -1,18 +1,27 @@
 package main
 
 import (
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/HarmanPreet-Singh-XYT/internal/database"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func sortMiddlewareAuth(c *gin.Context) database.User {
@@ -42,6 +51,179 @@ func createToken(id uuid.UUID, expiry time.Duration, tokenSecret string) (string
 	return tokenString, nil
 }
 
+const mfaChallengePurpose = "mfa_challenge"
+
+// Short-lived token proving the password step succeeded; checkAuth refuses it as an access token
+func createMfaChallengeToken(id uuid.UUID, tokenSecret string) (string, error) {
+	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":     id,
+		"iss":     "urlShortener",
+		"exp":     time.Now().Add(5 * time.Minute).Unix(),
+		"iat":     time.Now().Unix(),
+		"purpose": mfaChallengePurpose,
+	})
+	return claims.SignedString([]byte(tokenSecret))
+}
+
+func parseMfaChallengeToken(tokenString string, tokenSecret string) (uuid.UUID, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(tokenSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return uuid.Nil, errors.New("invalid or expired challenge token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != mfaChallengePurpose {
+		return uuid.Nil, errors.New("invalid challenge token")
+	}
+	sub, ok := claims["sub"].(string)
+	if !ok {
+		return uuid.Nil, errors.New("invalid challenge token subject")
+	}
+	return uuid.Parse(sub)
+}
+
+// Creates or rotates the user's refresh token and returns the token pair for a completed login
+func (cfg *apiCfg) startSession(c *gin.Context, user database.User) (AuthRes, error) {
+	if user.SuspendedAt.Valid {
+		return AuthRes{}, errAccountSuspended
+	}
+	refreshToken, err := createToken(user.ID, 7*24*time.Hour, cfg.jwtRefreshSecret)
+	if err != nil {
+		return AuthRes{}, err
+	}
+	errToken := cfg.db.CreateToken(c, database.CreateTokenParams{
+		UserID:       user.ID,
+		RefreshToken: refreshToken,
+	})
+	if errToken != nil {
+		var pqErr *pq.Error
+		if !errors.As(errToken, &pqErr) || pqErr.Code != "23505" { // Unique violation
+			return AuthRes{}, errToken
+		}
+		// The user already has a token row, rotate it instead
+		errToken = cfg.db.UpdateTokenByUserId(c, database.UpdateTokenByUserIdParams{
+			UserID:       user.ID,
+			RefreshToken: refreshToken,
+		})
+		if errToken != nil {
+			return AuthRes{}, errToken
+		}
+	}
+	accessToken, err := createToken(user.ID, 15*time.Minute, cfg.jwtSecret)
+	if err != nil {
+		return AuthRes{}, err
+	}
+	return AuthRes{
+		RefreshToken: refreshToken,
+		AccessToken:  accessToken,
+		Name:         user.Name,
+		Email:        user.Email,
+		Id:           user.ID,
+	}, nil
+}
+
+// Failed logins are counted per lowercased email, however the address was typed
+func loginAttemptEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// How long the client must wait before its next login attempt; zero when it may try now
+func (cfg *apiCfg) loginRetryAfter(c *gin.Context, email string) (time.Duration, error) {
+	now := time.Now()
+	since := now.Add(-loginFailureWindow)
+	account, err := cfg.db.CountFailedLoginAttemptsByEmail(c, database.CountFailedLoginAttemptsByEmailParams{
+		Email:     loginAttemptEmail(email),
+		CreatedAt: since,
+	})
+	if err != nil {
+		return 0, err
+	}
+	ip, err := cfg.db.CountFailedLoginAttemptsByIP(c, database.CountFailedLoginAttemptsByIPParams{
+		IpAddress: c.ClientIP(),
+		CreatedAt: since,
+	})
+	if err != nil {
+		return 0, err
+	}
+	var unlockAt time.Time
+	if account.Failures >= maxLoginFailuresPerAccount {
+		unlockAt = account.FirstFailureAt.Add(loginFailureWindow)
+	}
+	if ip.Failures >= maxLoginFailuresPerIP && ip.FirstFailureAt.Add(loginFailureWindow).After(unlockAt) {
+		unlockAt = ip.FirstFailureAt.Add(loginFailureWindow)
+	}
+	if unlockAt.IsZero() {
+		failures := max(account.Failures, ip.Failures)
+		if failures == 0 {
+			return 0, nil
+		}
+		last := account.LastFailureAt
+		if ip.LastFailureAt.After(last) {
+			last = ip.LastFailureAt
+		}
+		unlockAt = last.Add(loginFailureDelay(failures))
+	}
+	return max(unlockAt.Sub(now), 0), nil
+}
+
+// Answers 429 with Retry-After instead of holding the request open
+func abortLoginRetryAfter(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+}
+
+const (
+	maxLoginFailuresPerAccount = 5
+	maxLoginFailuresPerIP      = 20
+	loginFailureWindow         = 15 * time.Minute
+	loginBaseDelay             = 500 * time.Millisecond
+	loginMaxDelay              = 8 * time.Second
+	recentSignInsLimit         = 20
+)
+
+// Used for unknown emails so a failed login costs the same as a wrong password
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("urlShortener-dummy-password"), bcrypt.DefaultCost)
+
+// Doubles the delay for every consecutive failure, capped at loginMaxDelay
+func loginFailureDelay(failures int64) time.Duration {
+	delay := loginBaseDelay
+	for i := int64(1); i < failures && delay < loginMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, loginMaxDelay)
+}
+
+func (cfg *apiCfg) recordLoginAttempt(c *gin.Context, userID uuid.UUID, email string, success bool) {
+	err := cfg.db.CreateLoginAttempt(c, database.CreateLoginAttemptParams{
+		UserID:    uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		Email:     loginAttemptEmail(email),
+		IpAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Success:   success,
+	})
+	if err != nil {
+		log.Printf("Failed to record login attempt: %v", err)
+	}
+}
+
+// Returns a random URL-safe token for links sent by email; only its hash is stored
+func generateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
 
 func GenerateRandomString(length int) string {
@@ -52,6 +234,63 @@ func GenerateRandomString(length int) string {
 	return string(b)
 }
 
+func newAnalytics() Analytics {
+	return Analytics{
+		ByCountry:  map[string]int{},
+		ByReferrer: map[string]int{},
+		BySource:   map[string]int{},
+		ByChannel:  map[string]int{},
+		UTMBreakdown: UTMB{
+			UTMSource:   map[string]int{},
+			UTMMedium:   map[string]int{},
+			UTMCampaign: map[string]int{},
+		},
+		ClicksByDate: map[string]int{},
+		DeviceSummary: DeviceAnalytics{
+			DeviceType:       map[string]int{},
+			Platform:         map[string]int{},
+			Language:         map[string]int{},
+			ScreenResolution: map[string]int{},
+			Timezone:         map[string]int{},
+			UserAgents:       map[string]int{},
+		},
+	}
+}
+
+func uuidPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func defaultString(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// An optional query string filter; absent or empty means no filter
+func nullQuery(c *gin.Context, key string) sql.NullString {
+	value := strings.TrimSpace(c.Query(key))
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullUUIDFromPtr(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
 func sortAnalyticsData(data *Analytics, rows []database.AnalyticsRetrievalRow) {
 	for _, val := range rows {
 		data.TotalClicks++
@@ -62,8 +301,14 @@ func sortAnalyticsData(data *Analytics, rows []database.AnalyticsRetrievalRow) {
 		if val.Country != "" {
 			data.ByCountry[val.Country]++
 		}
-		if val.Referrer != "" {
-			data.ByReferrer[val.Referrer]++
+		if val.ReferrerDomain != "" {
+			data.ByReferrer[val.ReferrerDomain]++
+		}
+		if val.ReferrerSource != "" {
+			data.BySource[val.ReferrerSource]++
+		}
+		if val.ReferrerChannel != "" {
+			data.ByChannel[val.ReferrerChannel]++
 		}
 
 		date := val.CreatedAt.Format("2006-01-02")
@@ -122,42 +367,67 @@ func IPLocation(ip string) (string, error) {
 	json.NewDecoder(resp.Body).Decode(&resParameters)
 	return resParameters.Country, nil
 }
-func (cfg *apiCfg) SaveAnalytics(c *gin.Context, shortLinkId uuid.UUID, data RedirectReq) {
-	ip := c.ClientIP()
-	country := "Unknown"
-	if ip != "::1" && ip != "127.0.0.1" && ip != "localhost" {
-		location, err := IPLocation(ip)
-		if err != nil {
-			log.Fatal("Failed to get IP location")
-		} else {
-			country = location
-		}
-	}
-	clickID, err := cfg.db.CreateClick(c, database.CreateClickParams{
-		ShortLinkID: shortLinkId,
-		IpAddress:   ip,
-		Country:     country,
-		Referrer:    data.Referrer,
-		IsUnique:    data.IsUnique,
-		UtmSource:   data.UTM.UTMSource,
-		UtmMedium:   data.UTM.UTMMedium,
-		UtmCampaign: data.UTM.UTMCampaign,
-	})
+
+// Stores the click, anonymized per its privacy mode; opted-out visitors are only counted
+func (cfg *apiCfg) SaveAnalytics(c *gin.Context, link database.ShortLink, data RedirectReq) {
+	mode, err := cfg.effectivePrivacyMode(c, link)
 	if err != nil {
-		log.Fatal("Failed to create click analytic")
+		log.Printf("Failed to get privacy mode: %v", err)
 		return
 	}
-	err1 := cfg.db.CreateDevice(c, database.CreateDeviceParams{
-		ClickID:    clickID,
-		UserAgent:  data.Device.UserAgent,
-		DeviceType: data.Device.DeviceType,
-		Language:   data.Device.Language,
-		Platform:   data.Device.Platform,
-		Resolution: data.Device.ScreenResolution,
-		Timezone:   data.Device.Timezone,
-	})
-	if err1 != nil {
-		log.Fatal("Failed to create device analytic")
+	private := mode != privacyModeOff
+	clickParams := database.CreateClickParams{ShortLinkID: link.ID, Country: "Unknown"}
+	device := database.CreateDeviceParams{}
+	if !trackingOptOut(c) {
+		ip := c.ClientIP()
+		domain, source, channel := cfg.classifyReferrer(data.Referrer, data.UTM.UTMMedium)
+		clickParams = database.CreateClickParams{
+			ShortLinkID:     link.ID,
+			IpAddress:       cfg.anonymizeIP(ip, mode),
+			Country:         cfg.lookupCountry(ip, private),
+			Referrer:        data.Referrer,
+			IsUnique:        data.IsUnique,
+			UtmSource:       data.UTM.UTMSource,
+			UtmMedium:       data.UTM.UTMMedium,
+			UtmCampaign:     data.UTM.UTMCampaign,
+			ReferrerDomain:  domain,
+			ReferrerSource:  source,
+			ReferrerChannel: channel,
+		}
+		device = database.CreateDeviceParams{
+			DeviceType: data.Device.DeviceType,
+			Platform:   data.Device.Platform,
+		}
+		if !private {
+			device.UserAgent = data.Device.UserAgent
+			device.Language = data.Device.Language
+			device.Resolution = data.Device.ScreenResolution
+			device.Timezone = data.Device.Timezone
+		}
+	}
+	clickID, err := cfg.db.CreateClick(c, clickParams)
+	if err != nil {
+		log.Printf("Failed to create click analytic: %v", err)
 		return
 	}
+	device.ClickID = clickID
+	if err := cfg.db.CreateDevice(c, device); err != nil {
+		log.Printf("Failed to create device analytic: %v", err)
+		return
+	}
+	event := ClickEvent{
+		ShortLinkID: link.ID,
+		Slug:        link.Slug,
+		CampaignID:  uuidPtr(link.CampaignID),
+		Country:     clickParams.Country,
+		DeviceType:  device.DeviceType,
+		Platform:    device.Platform,
+		Referrer:    clickParams.ReferrerDomain,
+		Source:      clickParams.ReferrerSource,
+		Channel:     clickParams.ReferrerChannel,
+		IsUnique:    clickParams.IsUnique,
+		CreatedAt:   time.Now().UTC(),
+	}
+	cfg.broadcastClick(c, event)
+	cfg.emitWebhookEvent(c, link.UserID, webhookClickRecorded, event)
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+)
+
+const (
+	// The link's values replace any the destination URL already carries
+	utmPolicyOverride = "override"
+	// Values already present on the destination URL win
+	utmPolicyKeep = "keep"
+
+	maxExtraParams      = 20
+	maxQueryParamLength = 256
+)
+
+type queryParam struct {
+	key   string
+	value string
+}
+
+func validateUTMPolicy(policy string) error {
+	if policy != utmPolicyOverride && policy != utmPolicyKeep {
+		return errors.New("utm_policy must be override or keep")
+	}
+	return nil
+}
+
+func validateExtraParams(params map[string]string) error {
+	if len(params) > maxExtraParams {
+		return fmt.Errorf("At most %d extra parameters are allowed", maxExtraParams)
+	}
+	for key, value := range params {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("Extra parameter names can't be empty")
+		}
+		if len(key) > maxQueryParamLength || len(value) > maxQueryParamLength {
+			return fmt.Errorf("Extra parameters must be at most %d characters", maxQueryParamLength)
+		}
+	}
+	return nil
+}
+
+func encodeExtraParams(params map[string]string) (json.RawMessage, error) {
+	if params == nil {
+		params = map[string]string{}
+	}
+	return json.Marshal(params)
+}
+
+func decodeExtraParams(raw json.RawMessage) map[string]string {
+	params := map[string]string{}
+	if len(raw) > 0 {
+		// A malformed value is treated like no extra parameters rather than breaking redirects
+		_ = json.Unmarshal(raw, &params)
+	}
+	return params
+}
+
+// UTM fields first, then the extra parameters sorted by name
+func linkQueryParams(link database.ShortLink) []queryParam {
+	params := []queryParam{}
+	for _, p := range []queryParam{
+		{"utm_source", link.UtmSource},
+		{"utm_medium", link.UtmMedium},
+		{"utm_campaign", link.UtmCampaign},
+		{"utm_term", link.UtmTerm},
+		{"utm_content", link.UtmContent},
+	} {
+		if p.value != "" {
+			params = append(params, p)
+		}
+	}
+	extra := decodeExtraParams(link.ExtraParams)
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		params = append(params, queryParam{key, extra[key]})
+	}
+	return params
+}
+
+// Builds the visitor's destination; how parameters merge is up to the link's utm_policy
+func buildDestinationURL(link database.ShortLink, incomingQuery string) (string, error) {
+	dest, err := url.Parse(link.OriginalUrl)
+	if err != nil {
+		return "", err
+	}
+	additions := linkQueryParams(link)
+	if link.PassQuery && incomingQuery != "" {
+		incoming, err := url.ParseQuery(strings.TrimPrefix(incomingQuery, "?"))
+		if err == nil {
+			seen := map[string]bool{}
+			for _, p := range additions {
+				seen[p.key] = true
+			}
+			keys := make([]string, 0, len(incoming))
+			for key := range incoming {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if seen[key] {
+					continue
+				}
+				for _, value := range incoming[key] {
+					additions = append(additions, queryParam{key, value})
+				}
+			}
+		}
+	}
+	if len(additions) == 0 {
+		return link.OriginalUrl, nil
+	}
+
+	adding := map[string]bool{}
+	for _, p := range additions {
+		adding[p.key] = true
+	}
+	existing := map[string]bool{}
+	parts := []string{}
+	for _, part := range strings.Split(dest.RawQuery, "&") {
+		if part == "" {
+			continue
+		}
+		rawKey, _, _ := strings.Cut(part, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		existing[key] = true
+		if link.UtmPolicy != utmPolicyKeep && adding[key] {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	for _, p := range additions {
+		if link.UtmPolicy == utmPolicyKeep && existing[p.key] {
+			continue
+		}
+		parts = append(parts, url.QueryEscape(p.key)+"="+url.QueryEscape(p.value))
+	}
+	dest.RawQuery = strings.Join(parts, "&")
+	return dest.String(), nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	minNameLength     = 2
+	maxNameLength     = 100
+	maxEmailLength    = 254
+	minPasswordLength = 8
+	// bcrypt ignores everything after 72 bytes
+	maxPasswordBytes = 72
+
+	maxTagNameLength      = 50
+	maxFolderNameLength   = 100
+	maxCampaignNameLength = 100
+	maxBudgetNoteLength   = 1000
+)
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func validateName(name string) error {
+	name = strings.TrimSpace(name)
+	length := utf8.RuneCountInString(name)
+	if length < minNameLength || length > maxNameLength {
+		return errors.New("Name must be between 2 and 100 characters")
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return errors.New("Name contains invalid characters")
+		}
+	}
+	return nil
+}
+
+// Shared by tag and folder names: non-empty, bounded and free of control characters
+func validateLabel(field string, value string, maxLength int) error {
+	length := utf8.RuneCountInString(value)
+	if length == 0 || length > maxLength {
+		return fmt.Errorf("%s must be between 1 and %d characters", field, maxLength)
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%s contains invalid characters", field)
+		}
+	}
+	return nil
+}
+
+func validateAbsoluteURL(field string, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s must be an absolute http or https URL", field)
+	}
+	return nil
+}
+
+func validateTag(name string, color string) error {
+	if err := validateLabel("Tag name", name, maxTagNameLength); err != nil {
+		return err
+	}
+	if color != "" && !hexColorPattern.MatchString(color) {
+		return errors.New("Color must be a hex value like #1a2b3c")
+	}
+	return nil
+}
+
+func validateEmail(email string) error {
+	if len(email) > maxEmailLength {
+		return errors.New("Email address is too long")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@"):], ".") {
+		return errors.New("Please enter a valid email address")
+	}
+	return nil
+}
+
+func (cfg *apiCfg) validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return errors.New("Password must be at least 8 characters")
+	}
+	if len(password) > maxPasswordBytes {
+		return errors.New("Password must be at most 72 bytes")
+	}
+	var hasLower, hasUpper, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLower || !hasUpper || !hasDigit {
+		return errors.New("Password must contain at least one uppercase letter, one lowercase letter, and one number")
+	}
+	breached, err := cfg.breachList.IsBreached(password)
+	if err != nil {
+		// A broken list shouldn't lock everyone out of setting a password
+		log.Printf("Failed to check breached passwords: %v", err)
+	} else if breached {
+		return errors.New("This password has appeared in a data breach, please choose a different one")
+	}
+	return nil
+}
+
+type BreachChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// Pwned Passwords range files on disk: one file per SHA-1 prefix, lines of "SUFFIX:COUNT"
+type LocalBreachList struct {
+	dir string
+}
+
+func (b *LocalBreachList) IsBreached(password string) (bool, error) {
+	if b.dir == "" {
+		return false, nil
+	}
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+	f, err := os.Open(filepath.Join(b.dir, prefix))
+	if errors.Is(err, os.ErrNotExist) {
+		f, err = os.Open(filepath.Join(b.dir, prefix+".txt"))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultWebhookDeliveries = 50
+	maxWebhookDeliveries     = 200
+)
+
+func webhookRes(webhook database.Webhook) WebhookRes {
+	return WebhookRes{
+		ID:        webhook.ID,
+		URL:       webhook.Url,
+		Events:    webhook.Events,
+		IsActive:  webhook.IsActive,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func webhookDeliveryRes(delivery database.WebhookDelivery) WebhookDeliveryRes {
+	res := WebhookDeliveryRes{
+		ID:            delivery.ID,
+		Event:         delivery.Event,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		LastError:     delivery.LastError,
+		NextAttemptAt: nullTimePtr(delivery.NextAttemptAt),
+		DeliveredAt:   nullTimePtr(delivery.DeliveredAt),
+		CreatedAt:     delivery.CreatedAt,
+		Payload:       json.RawMessage(delivery.Payload),
+	}
+	if delivery.ResponseStatus.Valid {
+		res.ResponseStatus = &delivery.ResponseStatus.Int32
+	}
+	if delivery.Status != webhookStatusPending {
+		res.NextAttemptAt = nil
+	}
+	return res
+}
+
+// Checks the subscribed events and drops duplicates
+func parseWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, errors.New("Subscribe to at least one event")
+	}
+	seen := map[string]bool{}
+	parsed := []string{}
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if !webhookEvents[event] {
+			return nil, fmt.Errorf("Unknown event %q", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			parsed = append(parsed, event)
+		}
+	}
+	return parsed, nil
+}
+
+func (cfg *apiCfg) webhookFromParam(c *gin.Context, userID uuid.UUID) (database.Webhook, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return database.Webhook{}, false
+	}
+	webhook, err := cfg.db.RetrieveWebhookByUserIdANDId(c, database.RetrieveWebhookByUserIdANDIdParams{
+		UserID: userID,
+		ID:     id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+			return database.Webhook{}, false
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return database.Webhook{}, false
+	}
+	return webhook, true
+}
+
+func (cfg *apiCfg) GetWebhooks(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	webhooks, err := cfg.db.ListWebhooksByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data := []WebhookRes{}
+	for _, webhook := range webhooks {
+		data = append(data, webhookRes(webhook))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// Registers an endpoint. The signing secret is only ever returned here.
+func (cfg *apiCfg) CreateWebhook(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	var data WebhookReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	data.URL = strings.TrimSpace(data.URL)
+	if err := validatePublicURL("url", data.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	events, err := parseWebhookEvents(data.Events)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	existing, err := cfg.db.ListWebhooksByUserId(c, user.ID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook limit reached"})
+		return
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	webhook, err := cfg.db.CreateWebhook(c, database.CreateWebhookParams{
+		UserID: user.ID,
+		Url:    data.URL,
+		Secret: secret,
+		Events: events,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	res := webhookRes(webhook)
+	res.Secret = webhook.Secret
+	c.JSON(http.StatusCreated, res)
+}
+
+// Fields left out of the body keep their current value
+func (cfg *apiCfg) UpdateWebhook(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	webhook, ok := cfg.webhookFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	var data WebhookReq
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.AbortWithError(http.StatusBadRequest, gin.Error{Err: err})
+		return
+	}
+	params := database.UpdateWebhookParams{
+		UserID:   user.ID,
+		ID:       webhook.ID,
+		Url:      webhook.Url,
+		Events:   webhook.Events,
+		IsActive: webhook.IsActive,
+	}
+	if data.URL != "" {
+		params.Url = strings.TrimSpace(data.URL)
+		if err := validatePublicURL("url", params.Url); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if data.Events != nil {
+		events, err := parseWebhookEvents(data.Events)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		params.Events = events
+	}
+	if data.IsActive != nil {
+		params.IsActive = *data.IsActive
+	}
+	if _, err := cfg.db.UpdateWebhook(c, params); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+func (cfg *apiCfg) DeleteWebhook(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	webhook, ok := cfg.webhookFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	_, err := cfg.db.DeleteWebhookByUserIdANDId(c, database.DeleteWebhookByUserIdANDIdParams{
+		UserID: user.ID,
+		ID:     webhook.ID,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessRes{Success: true})
+}
+
+// Lists the most recent deliveries of the webhook, newest first
+func (cfg *apiCfg) GetWebhookDeliveries(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	webhook, ok := cfg.webhookFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	limit := defaultWebhookDeliveries
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxWebhookDeliveries {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+	deliveries, err := cfg.db.ListWebhookDeliveries(c, database.ListWebhookDeliveriesParams{
+		WebhookID: webhook.ID,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	data := []WebhookDeliveryRes{}
+	for _, delivery := range deliveries {
+		data = append(data, webhookDeliveryRes(delivery))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// Queues the same payload again as a new delivery with a fresh set of attempts
+func (cfg *apiCfg) RedeliverWebhook(c *gin.Context) {
+	user := sortMiddlewareAuth(c)
+	webhook, ok := cfg.webhookFromParam(c, user.ID)
+	if !ok {
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "delivery not found"})
+		return
+	}
+	delivery, err := cfg.db.RetrieveWebhookDeliveryByWebhookIdANDId(c, database.RetrieveWebhookDeliveryByWebhookIdANDIdParams{
+		WebhookID: webhook.ID,
+		ID:        deliveryID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "delivery not found"})
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	redelivery, err := cfg.db.CreateWebhookDelivery(c, database.CreateWebhookDeliveryParams{
+		WebhookID: webhook.ID,
+		Event:     delivery.Event,
+		Payload:   delivery.Payload,
+	})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, webhookDeliveryRes(redelivery))
+}
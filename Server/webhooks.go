@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	webhookLinkCreated     = "link.created"
+	webhookLinkUpdated     = "link.updated"
+	webhookLinkDeleted     = "link.deleted"
+	webhookLinkToggled     = "link.toggled"
+	webhookLinkExpired     = "link.expired"
+	webhookLinkBroken      = "link.broken"
+	webhookLinkQuarantined = "link.quarantined"
+	webhookClickRecorded   = "click.recorded"
+	webhookAlertTriggered  = "alert.triggered"
+
+	webhookStatusPending   = "pending"
+	webhookStatusSucceeded = "succeeded"
+	webhookStatusFailed    = "failed"
+
+	webhookDeliveryInterval = 10 * time.Second
+	webhookTimeout          = 10 * time.Second
+	webhookBatchSize        = 20
+	// Long enough for a whole batch to time out
+	webhookLeaseSeconds = 120
+	// Doubling from 30s, the 8th attempt comes a bit over an hour after the event
+	webhookBaseBackoff = 30 * time.Second
+	maxWebhookAttempts = 8
+	maxWebhooksPerUser = 20
+)
+
+var webhookEvents = map[string]bool{
+	webhookLinkCreated:     true,
+	webhookLinkUpdated:     true,
+	webhookLinkDeleted:     true,
+	webhookLinkToggled:     true,
+	webhookLinkExpired:     true,
+	webhookLinkBroken:      true,
+	webhookLinkQuarantined: true,
+	webhookClickRecorded:   true,
+	webhookAlertTriggered:  true,
+}
+
+// Doesn't follow redirects and only reaches public addresses
+var webhookClient = &http.Client{
+	Timeout:   webhookTimeout,
+	Transport: newPublicTransport(),
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Signs "<timestamp>.<body>" so receivers can reject replayed deliveries by their age
+func signWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookBackoff(attempts int32) time.Duration {
+	return webhookBaseBackoff << (attempts - 1)
+}
+
+func (cfg *apiCfg) webhookLinkData(link database.ShortLink) WebhookLink {
+	return WebhookLink{
+		Slug:        link.Slug,
+		ShortURL:    cfg.frontendOrigin + link.Slug,
+		OriginalURL: link.OriginalUrl,
+		Title:       link.Title,
+		IsActive:    link.IsActive.Bool,
+		Quarantined: link.QuarantinedAt.Valid,
+		UTMSource:   link.UtmSource,
+		UTMMedium:   link.UtmMedium,
+		UTMCampaign: link.UtmCampaign,
+		CampaignID:  uuidPtr(link.CampaignID),
+		FolderID:    uuidPtr(link.FolderID),
+	}
+}
+
+// Queues deliveries for the user's subscribed webhooks; failures are only logged
+func (cfg *apiCfg) emitWebhookEvent(ctx context.Context, userID uuid.UUID, event string, data any) {
+	webhooks, err := cfg.db.ListWebhooksForEvent(ctx, database.ListWebhooksForEventParams{
+		UserID: userID,
+		Event:  event,
+	})
+	if err != nil {
+		log.Printf("Failed to list webhooks: %v", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+	payload, err := json.Marshal(WebhookPayload{
+		ID:        uuid.New(),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		log.Printf("Failed to encode webhook payload: %v", err)
+		return
+	}
+	for _, webhook := range webhooks {
+		if _, err := cfg.db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			WebhookID: webhook.ID,
+			Event:     event,
+			Payload:   string(payload),
+		}); err != nil {
+			log.Printf("Failed to queue webhook delivery: %v", err)
+		}
+	}
+}
+
+func (cfg *apiCfg) emitLinkEvent(ctx context.Context, event string, link database.ShortLink) {
+	cfg.emitWebhookEvent(ctx, link.UserID, event, cfg.webhookLinkData(link))
+}
+
+// Sends every delivery that is due, a batch at a time and each batch in parallel
+func (cfg *apiCfg) deliverWebhooks(ctx context.Context) error {
+	for {
+		deliveries, err := cfg.db.ClaimDueWebhookDeliveries(ctx, database.ClaimDueWebhookDeliveriesParams{
+			LeaseSeconds: webhookLeaseSeconds,
+			BatchSize:    webhookBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery database.WebhookDelivery) {
+				defer wg.Done()
+				if err := cfg.deliverWebhook(ctx, delivery); err != nil {
+					log.Printf("Failed to record webhook delivery: %v", err)
+				}
+			}(delivery)
+		}
+		wg.Wait()
+		if len(deliveries) < webhookBatchSize {
+			return nil
+		}
+	}
+}
+
+func (cfg *apiCfg) deliverWebhook(ctx context.Context, delivery database.WebhookDelivery) error {
+	webhook, err := cfg.db.RetrieveWebhookById(ctx, delivery.WebhookID)
+	if err != nil {
+		return err
+	}
+	if !webhook.IsActive {
+		return cfg.db.MarkWebhookDeliveryFailed(ctx, database.MarkWebhookDeliveryFailedParams{
+			ID:        delivery.ID,
+			Status:    webhookStatusFailed,
+			LastError: "Webhook is disabled",
+		})
+	}
+	statusCode, sendErr := sendWebhook(ctx, webhook, delivery)
+	if sendErr == nil {
+		return cfg.db.MarkWebhookDeliverySucceeded(ctx, database.MarkWebhookDeliverySucceededParams{
+			ID:             delivery.ID,
+			ResponseStatus: sql.NullInt32{Int32: int32(statusCode), Valid: true},
+		})
+	}
+	params := database.MarkWebhookDeliveryFailedParams{
+		ID:             delivery.ID,
+		Status:         webhookStatusFailed,
+		ResponseStatus: sql.NullInt32{Int32: int32(statusCode), Valid: statusCode != 0},
+		LastError:      sendErr.Error(),
+	}
+	if attempts := delivery.Attempts + 1; attempts < maxWebhookAttempts {
+		params.Status = webhookStatusPending
+		params.NextAttemptAt = sql.NullTime{Time: time.Now().Add(webhookBackoff(attempts)), Valid: true}
+	}
+	return cfg.db.MarkWebhookDeliveryFailed(ctx, params)
+}
+
+// Posts the payload and returns the response status. Anything but a 2xx is an error.
+func sendWebhook(ctx context.Context, webhook database.Webhook, delivery database.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "QuickLink-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", delivery.ID.String())
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Signature", fmt.Sprintf("t=%d,v1=%s", timestamp, signWebhook(webhook.Secret, timestamp, body)))
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	// The body is never read: what the endpoint answers is not shown back to the user
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Link updates don't return the row, so the current state is read back for the payload
+func (cfg *apiCfg) emitLinkUpdated(c *gin.Context, userID uuid.UUID, slug string) {
+	link, err := cfg.db.RetrieveShortLinkBySlugNUserId(c, database.RetrieveShortLinkBySlugNUserIdParams{
+		UserID: userID,
+		Slug:   slug,
+	})
+	if err != nil {
+		log.Printf("Failed to load updated link: %v", err)
+		return
+	}
+	cfg.emitLinkEvent(c, webhookLinkUpdated, link)
+}
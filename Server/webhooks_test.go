@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HarmanPreet-Singh-XYT/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// A database/sql driver that answers each sqlc query by its name with scripted rows
+type fakeDB struct {
+	mu      sync.Mutex
+	results map[string][][]driver.Value
+	calls   map[string][][]driver.Value
+}
+
+var fakeQueryName = regexp.MustCompile(`-- name: (\w+)`)
+
+func newFakeDB(t *testing.T) (*fakeDB, *sql.DB) {
+	t.Helper()
+	fake := &fakeDB{results: map[string][][]driver.Value{}, calls: map[string][][]driver.Value{}}
+	conn := sql.OpenDB(fakeConnector{fake})
+	t.Cleanup(func() { conn.Close() })
+	return fake, conn
+}
+
+// Makes the named query return one row per value, each a generated struct or a single column
+func (f *fakeDB) returns(name string, values ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, v := range values {
+		f.results[name] = append(f.results[name], fakeRow(v))
+	}
+}
+
+func (f *fakeDB) called(name string) [][]driver.Value {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[name]
+}
+
+func (f *fakeDB) run(query string, args []driver.Value) driver.Rows {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := ""
+	if m := fakeQueryName.FindStringSubmatch(query); m != nil {
+		name = m[1]
+	}
+	f.calls[name] = append(f.calls[name], args)
+	return &fakeRows{rows: f.results[name]}
+}
+
+func fakeRow(v any) []driver.Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Struct || rv.Type() == reflect.TypeOf(time.Time{}) {
+		return []driver.Value{fakeValue(rv)}
+	}
+	if _, ok := v.(driver.Valuer); ok {
+		return []driver.Value{fakeValue(rv)}
+	}
+	row := make([]driver.Value, rv.NumField())
+	for i := range row {
+		row[i] = fakeValue(rv.Field(i))
+	}
+	return row
+}
+
+func fakeValue(v reflect.Value) driver.Value {
+	if valuer, ok := v.Interface().(driver.Valuer); ok {
+		value, _ := valuer.Value()
+		return value
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			return []byte("{" + strings.Join(v.Interface().([]string), ",") + "}")
+		}
+		return v.Bytes()
+	}
+	return v.Interface()
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.run(s.query, args)
+	return driver.RowsAffected(1), nil
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.db.run(s.query, args), nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestUpdateSlugEmitsLinkUpdated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake, conn := newFakeDB(t)
+	cfg := &apiCfg{db: database.New(conn), conn: conn, frontendOrigin: "https://short.test/"}
+	user := database.User{ID: uuid.New()}
+	renamed := database.ShortLink{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Slug:        "renamed",
+		OriginalUrl: "https://example.com/page",
+		ExtraParams: json.RawMessage("{}"),
+		CreatedAt:   time.Now(),
+	}
+	fake.returns("IsSlugQuarantined", false)
+	fake.returns("UpdateShortLinkSlug", renamed)
+	fake.returns("ListWebhooksForEvent", database.Webhook{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Url:       "https://hooks.test/",
+		Events:    []string{webhookLinkUpdated},
+		IsActive:  true,
+		CreatedAt: time.Now(),
+	})
+	fake.returns("CreateWebhookDelivery", database.WebhookDelivery{ID: uuid.New(), CreatedAt: time.Now()})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/link/original", strings.NewReader(`{"slug":"renamed"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "slug", Value: "original"}}
+	c.Set("currentUser", user)
+	cfg.UpdateSlug(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if audits := fake.called("CreateAuditLogEntry"); len(audits) != 1 {
+		t.Fatalf("wrote %d audit entries, want 1", len(audits))
+	}
+	listed := fake.called("ListWebhooksForEvent")
+	if len(listed) != 1 || listed[0][1] != webhookLinkUpdated {
+		t.Fatalf("webhooks listed for %v, want one %s lookup", listed, webhookLinkUpdated)
+	}
+	deliveries := fake.called("CreateWebhookDelivery")
+	if len(deliveries) != 1 {
+		t.Fatalf("queued %d deliveries, want 1", len(deliveries))
+	}
+	var payload struct {
+		Event string      `json:"event"`
+		Data  WebhookLink `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(deliveries[0][2].(string)), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != webhookLinkUpdated || payload.Data.Slug != "renamed" || payload.Data.ShortURL != "https://short.test/renamed" {
+		t.Fatalf("payload %+v, want link.updated for the renamed slug", payload)
+	}
+}